@@ -0,0 +1,855 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: geoid/v1/geoid.proto
+
+package geoidv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Level int32
+
+const (
+	Level_LEVEL_UNSPECIFIED Level = 0
+	Level_LEVEL_STATE       Level = 1
+	Level_LEVEL_CITY        Level = 2
+	Level_LEVEL_DISTRICT    Level = 3
+	Level_LEVEL_VILLAGE     Level = 4
+)
+
+// Enum value maps for Level.
+var (
+	Level_name = map[int32]string{
+		0: "LEVEL_UNSPECIFIED",
+		1: "LEVEL_STATE",
+		2: "LEVEL_CITY",
+		3: "LEVEL_DISTRICT",
+		4: "LEVEL_VILLAGE",
+	}
+	Level_value = map[string]int32{
+		"LEVEL_UNSPECIFIED": 0,
+		"LEVEL_STATE":       1,
+		"LEVEL_CITY":        2,
+		"LEVEL_DISTRICT":    3,
+		"LEVEL_VILLAGE":     4,
+	}
+)
+
+func (x Level) Enum() *Level {
+	p := new(Level)
+	*p = x
+	return p
+}
+
+func (x Level) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Level) Descriptor() protoreflect.EnumDescriptor {
+	return file_geoid_v1_geoid_proto_enumTypes[0].Descriptor()
+}
+
+func (Level) Type() protoreflect.EnumType {
+	return &file_geoid_v1_geoid_proto_enumTypes[0]
+}
+
+func (x Level) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Level.Descriptor instead.
+func (Level) EnumDescriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{0}
+}
+
+type Region struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Level         Level                  `protobuf:"varint,3,opt,name=level,proto3,enum=geoid.v1.Level" json:"level,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Region) Reset() {
+	*x = Region{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Region) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Region) ProtoMessage() {}
+
+func (x *Region) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Region.ProtoReflect.Descriptor instead.
+func (*Region) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Region) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Region) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Region) GetLevel() Level {
+	if x != nil {
+		return x.Level
+	}
+	return Level_LEVEL_UNSPECIFIED
+}
+
+func (x *Region) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type GetRegionRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Code             string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	IncludeAncestors bool                   `protobuf:"varint,2,opt,name=include_ancestors,json=includeAncestors,proto3" json:"include_ancestors,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetRegionRequest) Reset() {
+	*x = GetRegionRequest{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegionRequest) ProtoMessage() {}
+
+func (x *GetRegionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegionRequest.ProtoReflect.Descriptor instead.
+func (*GetRegionRequest) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetRegionRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *GetRegionRequest) GetIncludeAncestors() bool {
+	if x != nil {
+		return x.IncludeAncestors
+	}
+	return false
+}
+
+type GetRegionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Region        *Region                `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	Ancestors     []*Region              `protobuf:"bytes,2,rep,name=ancestors,proto3" json:"ancestors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegionResponse) Reset() {
+	*x = GetRegionResponse{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegionResponse) ProtoMessage() {}
+
+func (x *GetRegionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegionResponse.ProtoReflect.Descriptor instead.
+func (*GetRegionResponse) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetRegionResponse) GetRegion() *Region {
+	if x != nil {
+		return x.Region
+	}
+	return nil
+}
+
+func (x *GetRegionResponse) GetAncestors() []*Region {
+	if x != nil {
+		return x.Ancestors
+	}
+	return nil
+}
+
+type ListChildrenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	PageToken     string                 `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	Sort          string                 `protobuf:"bytes,5,opt,name=sort,proto3" json:"sort,omitempty"`
+	Query         string                 `protobuf:"bytes,6,opt,name=query,proto3" json:"query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChildrenRequest) Reset() {
+	*x = ListChildrenRequest{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChildrenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChildrenRequest) ProtoMessage() {}
+
+func (x *ListChildrenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChildrenRequest.ProtoReflect.Descriptor instead.
+func (*ListChildrenRequest) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListChildrenRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ListChildrenRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListChildrenRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListChildrenRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListChildrenRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListChildrenRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+type ListChildrenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Regions       []*Region              `protobuf:"bytes,1,rep,name=regions,proto3" json:"regions,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChildrenResponse) Reset() {
+	*x = ListChildrenResponse{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChildrenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChildrenResponse) ProtoMessage() {}
+
+func (x *ListChildrenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChildrenResponse.ProtoReflect.Descriptor instead.
+func (*ListChildrenResponse) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListChildrenResponse) GetRegions() []*Region {
+	if x != nil {
+		return x.Regions
+	}
+	return nil
+}
+
+func (x *ListChildrenResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListChildrenResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type BatchGetRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Codes            []string               `protobuf:"bytes,1,rep,name=codes,proto3" json:"codes,omitempty"`
+	IncludeAncestors bool                   `protobuf:"varint,2,opt,name=include_ancestors,json=includeAncestors,proto3" json:"include_ancestors,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BatchGetRequest) Reset() {
+	*x = BatchGetRequest{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetRequest) ProtoMessage() {}
+
+func (x *BatchGetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetRequest) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchGetRequest) GetCodes() []string {
+	if x != nil {
+		return x.Codes
+	}
+	return nil
+}
+
+func (x *BatchGetRequest) GetIncludeAncestors() bool {
+	if x != nil {
+		return x.IncludeAncestors
+	}
+	return false
+}
+
+type BatchGetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*BatchItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetResponse) Reset() {
+	*x = BatchGetResponse{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetResponse) ProtoMessage() {}
+
+func (x *BatchGetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetResponse) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchGetResponse) GetItems() []*BatchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BatchItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Region        *Region                `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	Ancestors     []*Region              `protobuf:"bytes,3,rep,name=ancestors,proto3" json:"ancestors,omitempty"`
+	Error         *BatchError            `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchItem) Reset() {
+	*x = BatchItem{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchItem) ProtoMessage() {}
+
+func (x *BatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchItem.ProtoReflect.Descriptor instead.
+func (*BatchItem) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchItem) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *BatchItem) GetRegion() *Region {
+	if x != nil {
+		return x.Region
+	}
+	return nil
+}
+
+func (x *BatchItem) GetAncestors() []*Region {
+	if x != nil {
+		return x.Ancestors
+	}
+	return nil
+}
+
+func (x *BatchItem) GetError() *BatchError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type BatchError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchError) Reset() {
+	*x = BatchError{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchError) ProtoMessage() {}
+
+func (x *BatchError) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchError.ProtoReflect.Descriptor instead.
+func (*BatchError) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BatchError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *BatchError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ExportRegionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         Level                  `protobuf:"varint,1,opt,name=level,proto3,enum=geoid.v1.Level" json:"level,omitempty"`
+	Within        string                 `protobuf:"bytes,2,opt,name=within,proto3" json:"within,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportRegionsRequest) Reset() {
+	*x = ExportRegionsRequest{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportRegionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportRegionsRequest) ProtoMessage() {}
+
+func (x *ExportRegionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportRegionsRequest.ProtoReflect.Descriptor instead.
+func (*ExportRegionsRequest) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExportRegionsRequest) GetLevel() Level {
+	if x != nil {
+		return x.Level
+	}
+	return Level_LEVEL_UNSPECIFIED
+}
+
+func (x *ExportRegionsRequest) GetWithin() string {
+	if x != nil {
+		return x.Within
+	}
+	return ""
+}
+
+type ExportRegionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Region        *Region                `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	Parents       []*Region              `protobuf:"bytes,2,rep,name=parents,proto3" json:"parents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportRegionsResponse) Reset() {
+	*x = ExportRegionsResponse{}
+	mi := &file_geoid_v1_geoid_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportRegionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportRegionsResponse) ProtoMessage() {}
+
+func (x *ExportRegionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_geoid_v1_geoid_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportRegionsResponse.ProtoReflect.Descriptor instead.
+func (*ExportRegionsResponse) Descriptor() ([]byte, []int) {
+	return file_geoid_v1_geoid_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ExportRegionsResponse) GetRegion() *Region {
+	if x != nil {
+		return x.Region
+	}
+	return nil
+}
+
+func (x *ExportRegionsResponse) GetParents() []*Region {
+	if x != nil {
+		return x.Parents
+	}
+	return nil
+}
+
+var File_geoid_v1_geoid_proto protoreflect.FileDescriptor
+
+const file_geoid_v1_geoid_proto_rawDesc = "" +
+	"\n" +
+	"\x14geoid/v1/geoid.proto\x12\bgeoid.v1\"k\n" +
+	"\x06Region\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12%\n" +
+	"\x05level\x18\x03 \x01(\x0e2\x0f.geoid.v1.LevelR\x05level\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\"S\n" +
+	"\x10GetRegionRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12+\n" +
+	"\x11include_ancestors\x18\x02 \x01(\bR\x10includeAncestors\"m\n" +
+	"\x11GetRegionResponse\x12(\n" +
+	"\x06region\x18\x01 \x01(\v2\x10.geoid.v1.RegionR\x06region\x12.\n" +
+	"\tancestors\x18\x02 \x03(\v2\x10.geoid.v1.RegionR\tancestors\"\xa0\x01\n" +
+	"\x13ListChildrenRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12\x12\n" +
+	"\x04sort\x18\x05 \x01(\tR\x04sort\x12\x14\n" +
+	"\x05query\x18\x06 \x01(\tR\x05query\"\x80\x01\n" +
+	"\x14ListChildrenResponse\x12*\n" +
+	"\aregions\x18\x01 \x03(\v2\x10.geoid.v1.RegionR\aregions\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\"T\n" +
+	"\x0fBatchGetRequest\x12\x14\n" +
+	"\x05codes\x18\x01 \x03(\tR\x05codes\x12+\n" +
+	"\x11include_ancestors\x18\x02 \x01(\bR\x10includeAncestors\"=\n" +
+	"\x10BatchGetResponse\x12)\n" +
+	"\x05items\x18\x01 \x03(\v2\x13.geoid.v1.BatchItemR\x05items\"\xa5\x01\n" +
+	"\tBatchItem\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12(\n" +
+	"\x06region\x18\x02 \x01(\v2\x10.geoid.v1.RegionR\x06region\x12.\n" +
+	"\tancestors\x18\x03 \x03(\v2\x10.geoid.v1.RegionR\tancestors\x12*\n" +
+	"\x05error\x18\x04 \x01(\v2\x14.geoid.v1.BatchErrorR\x05error\":\n" +
+	"\n" +
+	"BatchError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"U\n" +
+	"\x14ExportRegionsRequest\x12%\n" +
+	"\x05level\x18\x01 \x01(\x0e2\x0f.geoid.v1.LevelR\x05level\x12\x16\n" +
+	"\x06within\x18\x02 \x01(\tR\x06within\"m\n" +
+	"\x15ExportRegionsResponse\x12(\n" +
+	"\x06region\x18\x01 \x01(\v2\x10.geoid.v1.RegionR\x06region\x12*\n" +
+	"\aparents\x18\x02 \x03(\v2\x10.geoid.v1.RegionR\aparents*f\n" +
+	"\x05Level\x12\x15\n" +
+	"\x11LEVEL_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vLEVEL_STATE\x10\x01\x12\x0e\n" +
+	"\n" +
+	"LEVEL_CITY\x10\x02\x12\x12\n" +
+	"\x0eLEVEL_DISTRICT\x10\x03\x12\x11\n" +
+	"\rLEVEL_VILLAGE\x10\x042\xb8\x02\n" +
+	"\n" +
+	"GeoService\x12D\n" +
+	"\tGetRegion\x12\x1a.geoid.v1.GetRegionRequest\x1a\x1b.geoid.v1.GetRegionResponse\x12M\n" +
+	"\fListChildren\x12\x1d.geoid.v1.ListChildrenRequest\x1a\x1e.geoid.v1.ListChildrenResponse\x12A\n" +
+	"\bBatchGet\x12\x19.geoid.v1.BatchGetRequest\x1a\x1a.geoid.v1.BatchGetResponse\x12R\n" +
+	"\rExportRegions\x12\x1e.geoid.v1.ExportRegionsRequest\x1a\x1f.geoid.v1.ExportRegionsResponse0\x01B5Z3github.com/ikhsanfalakh/geo-id/api/geoid/v1;geoidv1b\x06proto3"
+
+var (
+	file_geoid_v1_geoid_proto_rawDescOnce sync.Once
+	file_geoid_v1_geoid_proto_rawDescData []byte
+)
+
+func file_geoid_v1_geoid_proto_rawDescGZIP() []byte {
+	file_geoid_v1_geoid_proto_rawDescOnce.Do(func() {
+		file_geoid_v1_geoid_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_geoid_v1_geoid_proto_rawDesc), len(file_geoid_v1_geoid_proto_rawDesc)))
+	})
+	return file_geoid_v1_geoid_proto_rawDescData
+}
+
+var file_geoid_v1_geoid_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_geoid_v1_geoid_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_geoid_v1_geoid_proto_goTypes = []any{
+	(Level)(0),                    // 0: geoid.v1.Level
+	(*Region)(nil),                // 1: geoid.v1.Region
+	(*GetRegionRequest)(nil),      // 2: geoid.v1.GetRegionRequest
+	(*GetRegionResponse)(nil),     // 3: geoid.v1.GetRegionResponse
+	(*ListChildrenRequest)(nil),   // 4: geoid.v1.ListChildrenRequest
+	(*ListChildrenResponse)(nil),  // 5: geoid.v1.ListChildrenResponse
+	(*BatchGetRequest)(nil),       // 6: geoid.v1.BatchGetRequest
+	(*BatchGetResponse)(nil),      // 7: geoid.v1.BatchGetResponse
+	(*BatchItem)(nil),             // 8: geoid.v1.BatchItem
+	(*BatchError)(nil),            // 9: geoid.v1.BatchError
+	(*ExportRegionsRequest)(nil),  // 10: geoid.v1.ExportRegionsRequest
+	(*ExportRegionsResponse)(nil), // 11: geoid.v1.ExportRegionsResponse
+}
+var file_geoid_v1_geoid_proto_depIdxs = []int32{
+	0,  // 0: geoid.v1.Region.level:type_name -> geoid.v1.Level
+	1,  // 1: geoid.v1.GetRegionResponse.region:type_name -> geoid.v1.Region
+	1,  // 2: geoid.v1.GetRegionResponse.ancestors:type_name -> geoid.v1.Region
+	1,  // 3: geoid.v1.ListChildrenResponse.regions:type_name -> geoid.v1.Region
+	8,  // 4: geoid.v1.BatchGetResponse.items:type_name -> geoid.v1.BatchItem
+	1,  // 5: geoid.v1.BatchItem.region:type_name -> geoid.v1.Region
+	1,  // 6: geoid.v1.BatchItem.ancestors:type_name -> geoid.v1.Region
+	9,  // 7: geoid.v1.BatchItem.error:type_name -> geoid.v1.BatchError
+	0,  // 8: geoid.v1.ExportRegionsRequest.level:type_name -> geoid.v1.Level
+	1,  // 9: geoid.v1.ExportRegionsResponse.region:type_name -> geoid.v1.Region
+	1,  // 10: geoid.v1.ExportRegionsResponse.parents:type_name -> geoid.v1.Region
+	2,  // 11: geoid.v1.GeoService.GetRegion:input_type -> geoid.v1.GetRegionRequest
+	4,  // 12: geoid.v1.GeoService.ListChildren:input_type -> geoid.v1.ListChildrenRequest
+	6,  // 13: geoid.v1.GeoService.BatchGet:input_type -> geoid.v1.BatchGetRequest
+	10, // 14: geoid.v1.GeoService.ExportRegions:input_type -> geoid.v1.ExportRegionsRequest
+	3,  // 15: geoid.v1.GeoService.GetRegion:output_type -> geoid.v1.GetRegionResponse
+	5,  // 16: geoid.v1.GeoService.ListChildren:output_type -> geoid.v1.ListChildrenResponse
+	7,  // 17: geoid.v1.GeoService.BatchGet:output_type -> geoid.v1.BatchGetResponse
+	11, // 18: geoid.v1.GeoService.ExportRegions:output_type -> geoid.v1.ExportRegionsResponse
+	15, // [15:19] is the sub-list for method output_type
+	11, // [11:15] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_geoid_v1_geoid_proto_init() }
+func file_geoid_v1_geoid_proto_init() {
+	if File_geoid_v1_geoid_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_geoid_v1_geoid_proto_rawDesc), len(file_geoid_v1_geoid_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_geoid_v1_geoid_proto_goTypes,
+		DependencyIndexes: file_geoid_v1_geoid_proto_depIdxs,
+		EnumInfos:         file_geoid_v1_geoid_proto_enumTypes,
+		MessageInfos:      file_geoid_v1_geoid_proto_msgTypes,
+	}.Build()
+	File_geoid_v1_geoid_proto = out.File
+	file_geoid_v1_geoid_proto_goTypes = nil
+	file_geoid_v1_geoid_proto_depIdxs = nil
+}
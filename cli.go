@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ikhsanfalakh/geo-id/internal/config"
+)
+
+// Exit codes shared by every command.
+const (
+	exitOK      = 0
+	exitFailure = 1 // the command ran and failed, e.g. a region was not found
+	exitUsage   = 2 // invalid arguments, flags or configuration
+)
+
+// command is a subcommand of the geo-id binary.
+type command struct {
+	name     string
+	synopsis string // arguments after the command name
+	summary  string
+	run      func(args []string) int
+}
+
+// commands lists the subcommands in the order shown by help. It is filled
+// in by init to break the reference cycle through runHelp.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "[flags]", "Start the HTTP, gRPC and admin servers (the default)", runServe},
+		{"import", "[-file wilayah.sql] [-source URL] [-json] [flags]", "Import the dataset from wilayah.sql into DATA_DIR", runImport},
+		{"validate", "[-json] [flags]", "Check the dataset in DATA_DIR and print its edition and counts", runValidate},
+		{"export", "[-format csv|ndjson|json] [-level LEVEL] [-within CODE] [-gzip] [-o FILE] [flags]", "Write the dataset as CSV, NDJSON or JSON", runExport},
+		{"lookup", "[-level LEVEL] [-limit N] [-json] [flags] CODE... | NAME", "Look up regions by code or search them by name", runLookup},
+		{"keys", "generate|list|check [-json] [flags]", "Generate and inspect API keys", runKeys},
+		{"config", "show [flags]", "Print the effective configuration with secrets redacted", runConfig},
+		{"help", "[COMMAND]", "Show help for geo-id or a command", runHelp},
+	}
+}
+
+// dispatch runs the command named by args[0]. Without a command, or when
+// args start with a flag, it runs serve, as before commands existed.
+func dispatch(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") && !isHelpFlag(args[0]) {
+		return runServe(args)
+	}
+	if isHelpFlag(args[0]) {
+		return runHelp(nil)
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "geo-id: unknown command %q\n\n", args[0])
+	printUsage(os.Stderr)
+	return exitUsage
+}
+
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// runHelp implements the "help" command.
+func runHelp(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stdout)
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] && cmd.name != "help" {
+			return cmd.run([]string{"-h"})
+		}
+	}
+	fmt.Fprintf(os.Stderr, "geo-id help: unknown command %q\n", args[0])
+	return exitUsage
+}
+
+// printUsage writes the command overview.
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, "Geo-ID serves and queries Indonesian administrative regions.\n\nUsage: geo-id <command> [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(w, "\nRun \"geo-id <command> -h\" for the arguments of a command. Every command\n"+
+		"accepts the configuration flags listed by \"geo-id serve -h\".\n\n"+
+		"Exit codes: 0 success, 1 failure, 2 invalid usage or configuration.\n")
+}
+
+// newFlagSet returns the flag set of a command. Its usage lists the
+// command's own flags, leaving out the configuration flags that
+// loadConfig adds.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("geo-id "+name, flag.ContinueOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		for _, cmd := range commands {
+			if cmd.name == name {
+				fmt.Fprintf(out, "Usage: geo-id %s %s\n\n%s.\n\n", cmd.name, cmd.synopsis, cmd.summary)
+			}
+		}
+		own := flag.NewFlagSet(name, flag.ContinueOnError)
+		own.SetOutput(out)
+		fs.VisitAll(func(f *flag.Flag) {
+			if !config.IsFlag(f.Name) {
+				own.Var(f.Value, f.Name, f.Usage)
+				own.Lookup(f.Name).DefValue = f.DefValue
+			}
+		})
+		own.PrintDefaults()
+		fmt.Fprintln(out, "\nConfiguration flags such as -data-dir and -config are accepted as well\n(see \"geo-id serve -h\").")
+	}
+	return fs
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/ikhsanfalakh/geo-id/internal/config"
+	"github.com/ikhsanfalakh/geo-id/internal/logging"
+)
+
+// runConfig implements the "config" command. "config show" prints the
+// effective configuration as a YAML config file, with secrets redacted.
+//
+//	geo-id config show [config flags]
+func runConfig(args []string) int {
+	fs := newFlagSet("config")
+	if len(args) == 0 || args[0] != "show" {
+		if len(args) > 0 && isHelpFlag(args[0]) {
+			fs.SetOutput(os.Stdout)
+			fs.Usage()
+			return exitOK
+		}
+		fs.Usage()
+		return exitUsage
+	}
+	cfg, code := loadConfig(fs, args[1:])
+	if cfg == nil {
+		return code
+	}
+	if !noArgs(fs) {
+		return exitUsage
+	}
+	if err := cfg.Show(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// loadConfig loads the configuration, with fs parsing the command's own
+// flags and the config flags in args, and installs the configured logger.
+// On failure it reports the error and returns a nil config and the exit
+// code. Commands taking no arguments check fs.NArg with noArgs.
+func loadConfig(fs *flag.FlagSet, args []string) (*config.Config, int) {
+	cfg, err := config.Load(fs, args)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return nil, exitOK
+	case errors.Is(err, config.ErrFlags):
+		return nil, exitUsage
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		return nil, exitUsage
+	}
+
+	// Load has validated the log settings
+	logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, exitUsage
+	}
+	slog.SetDefault(logger)
+	return cfg, 0
+}
+
+// noArgs reports an error and returns false when fs has arguments left.
+func noArgs(fs *flag.FlagSet) bool {
+	if fs.NArg() == 0 {
+		return true
+	}
+	fmt.Fprintf(os.Stderr, "%s: unexpected argument %q\n", fs.Name(), fs.Arg(0))
+	return false
+}
@@ -0,0 +1,66 @@
+// Command geoid shows how to use the geoid library with the embedded
+// dataset: looking up a code, walking up and down the hierarchy and
+// searching by name.
+//
+//	go run ./examples/geoid 32.73.01.1001
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid/embedded"
+)
+
+func main() {
+	code := "32.73"
+	if len(os.Args) > 1 {
+		code = os.Args[1]
+	}
+
+	ds, err := embedded.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Dataset: %s, %d regions\n", ds.Edition().Name, ds.Len())
+
+	region, err := ds.Region(code)
+	switch {
+	case errors.Is(err, geoid.ErrInvalidCode):
+		log.Fatalf("%s is not a region code", code)
+	case errors.Is(err, geoid.ErrNotFound):
+		log.Fatalf("%s does not exist", code)
+	case err != nil:
+		log.Fatal(err)
+	}
+	fmt.Printf("%s: %s (%s, %s)\n", region.Code, region.Name, region.Level(), region.Type())
+
+	ancestors, err := ds.Ancestors(code)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, a := range ancestors {
+		fmt.Printf("  in %s %s\n", a.Type(), a.Name)
+	}
+
+	children, err := ds.Children(code)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(children) > 0 {
+		fmt.Printf("  %d %s, the first is %s\n", len(children), region.Level().Child().Plural(), children[0].Name)
+	}
+
+	// Find regions of the same level sharing the last word of the name
+	name := strings.Fields(region.Name)
+	word := name[len(name)-1]
+	matches := ds.Search(word, geoid.SearchOptions{Level: region.Level(), Limit: 5})
+	fmt.Printf("%s named like %q:\n", region.Level().Plural(), word)
+	for _, m := range matches {
+		fmt.Printf("  %s %s\n", m.Code, m.Name)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ikhsanfalakh/geo-id/internal/export"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+// runExport implements the offline "export" command, which writes the same
+// output as GET /export straight from DATA_DIR.
+//
+//	geo-id export [-format csv|ndjson|json] [-level LEVEL] [-within CODE] [-gzip] [-o FILE] [config flags]
+func runExport(args []string) int {
+	fs := newFlagSet("export")
+	format := fs.String("format", export.FormatCSV, "output format: csv, ndjson or json")
+	level := fs.String("level", "", "only export regions of this level: state, city, district or village")
+	within := fs.String("within", "", "only export this region code and its descendants")
+	compress := fs.Bool("gzip", false, "gzip-compress the output")
+	output := fs.String("o", "", "write to this file instead of stdout")
+	cfg, code := loadConfig(fs, args)
+	if cfg == nil {
+		return code
+	}
+	if !noArgs(fs) {
+		return exitUsage
+	}
+
+	svc := service.NewLocationService(cfg.Data.Dir)
+	opts := export.Options{
+		Format: *format,
+		Level:  service.Level(*level),
+		Within: *within,
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "export:", err)
+			return exitFailure
+		}
+		defer f.Close()
+		out = f
+	}
+
+	bw := bufio.NewWriter(out)
+	var w io.Writer = bw
+	var gz *gzip.Writer
+	if *compress {
+		gz = gzip.NewWriter(bw)
+		w = gz
+	}
+
+	err := export.Write(context.Background(), w, svc, opts)
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export:", err)
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+// downloadTimeout bounds the download of wilayah.sql.
+const downloadTimeout = 5 * time.Minute
+
+// importResult is the JSON output of the import command.
+type importResult struct {
+	DataDir string             `json:"data_dir"`
+	Edition model.Edition      `json:"edition"`
+	Counts  model.RegionCounts `json:"counts"`
+	Orphans int                `json:"orphan_villages"`
+	Skipped int                `json:"skipped_rows"`
+}
+
+// runImport implements the "import" command, which regenerates DATA_DIR
+// from wilayah.sql, downloaded from -source or read from -file.
+//
+//	geo-id import [-file wilayah.sql] [-source URL] [-save FILE] [-json] [config flags]
+func runImport(args []string) int {
+	fs := newFlagSet("import")
+	file := fs.String("file", "", "read this wilayah.sql instead of downloading it")
+	source := fs.String("source", service.WilayahSQLURL, "URL of wilayah.sql, downloaded unless -file is set and recorded as the edition's source")
+	save := fs.String("save", "", "also save the downloaded wilayah.sql to this file")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	cfg, code := loadConfig(fs, args)
+	if cfg == nil {
+		return code
+	}
+	if !noArgs(fs) {
+		return exitUsage
+	}
+
+	var sql io.Reader
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "import:", err)
+			return exitFailure
+		}
+		defer f.Close()
+		sql = f
+	} else {
+		fmt.Fprintln(os.Stderr, "Downloading", *source)
+		body, err := download(*source, *save)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "import:", err)
+			return exitFailure
+		}
+		defer body.Close()
+		sql = body
+	}
+
+	dataset, err := service.ParseWilayahSQL(sql, *source)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return exitFailure
+	}
+	orphans, err := dataset.WriteDir(cfg.Data.Dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return exitFailure
+	}
+
+	result := importResult{
+		DataDir: cfg.Data.Dir,
+		Edition: dataset.Edition,
+		Counts:  dataset.Counts(),
+		Orphans: orphans,
+		Skipped: dataset.Skipped,
+	}
+	if *asJSON {
+		if err := printJSON(result); err != nil {
+			return exitFailure
+		}
+		return exitOK
+	}
+	fmt.Printf("Imported %s (updated %s) into %s\n",
+		result.Edition.Name, result.Edition.UpdatedAt.Format(time.DateOnly), result.DataDir)
+	fmt.Printf("States: %d, cities: %d, districts: %d, villages: %d\n",
+		result.Counts.States, result.Counts.Cities, result.Counts.Districts, result.Counts.Villages)
+	if orphans > 0 {
+		fmt.Printf("%d villages belong to districts missing from the source\n", orphans)
+	}
+	return exitOK
+}
+
+// download fetches url, copying the body to save when it is set.
+func download(url, save string) (io.ReadCloser, error) {
+	client := &http.Client{Timeout: downloadTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+	if save == "" {
+		return resp.Body, nil
+	}
+
+	defer resp.Body.Close()
+	f, err := os.Create(save)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("download %s: %w", url, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
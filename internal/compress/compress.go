@@ -0,0 +1,160 @@
+// Package compress negotiates and applies HTTP content codings: Brotli
+// (br), Zstandard (zstd) and gzip.
+package compress
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Supported content codings, in order of preference when a client accepts
+// several with the same quality.
+const (
+	Brotli   = "br"
+	Zstd     = "zstd"
+	Gzip     = "gzip"
+	Identity = ""
+)
+
+// Encodings lists the supported codings in order of preference.
+var Encodings = []string{Brotli, Zstd, Gzip}
+
+// Negotiate picks the coding to use for an Accept-Encoding header value,
+// returning Identity when none of the supported codings is acceptable.
+func Negotiate(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return Identity
+	}
+	quality := map[string]float64{}
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if name == "*" {
+			wildcard = q
+		} else {
+			quality[name] = q
+		}
+	}
+
+	best, bestQ := Identity, 0.0
+	for _, enc := range Encodings {
+		q, ok := quality[enc]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// NewWriter returns a writer that compresses to w with the given coding.
+// Close must be called to flush the compressed stream; it does not close w.
+func NewWriter(w io.Writer, encoding string) (io.WriteCloser, error) {
+	switch encoding {
+	case Brotli:
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression), nil
+	case Zstd:
+		enc, ok := zstdWriters.Get().(*zstd.Encoder)
+		if !ok {
+			var err error
+			if enc, err = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1)); err != nil {
+				return nil, err
+			}
+		}
+		enc.Reset(w)
+		return &zstdWriter{enc: enc}, nil
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	default:
+		return nopCloser{w}, nil
+	}
+}
+
+// zstdWriters pools the encoders of NewWriter: each allocates several
+// hundred kilobytes of buffers, too much to create per response. They
+// encode synchronously, without goroutines of their own.
+var zstdWriters sync.Pool
+
+// zstdWriter returns its encoder to zstdWriters on Close.
+type zstdWriter struct {
+	enc *zstd.Encoder
+}
+
+func (z *zstdWriter) Write(p []byte) (int, error) {
+	if z.enc == nil {
+		return 0, errWriterClosed
+	}
+	return z.enc.Write(p)
+}
+
+func (z *zstdWriter) Close() error {
+	if z.enc == nil {
+		return nil
+	}
+	err := z.enc.Close()
+	// Drop the reference to the destination before pooling the encoder.
+	z.enc.Reset(nil)
+	zstdWriters.Put(z.enc)
+	z.enc = nil
+	return err
+}
+
+var errWriterClosed = errors.New("compress: write after close")
+
+// brotliEncodeLevel is the Brotli quality used by Encode. On the region
+// lists, levels above 6 shrink the output by well under 1% while taking up
+// to thirty times longer.
+const brotliEncodeLevel = 6
+
+// zstdEncoder is shared by every Encode call; EncodeAll is safe for
+// concurrent use.
+var zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+
+// Encode compresses body with the given coding at a high compression level,
+// for bodies compressed once and served many times.
+func Encode(body []byte, encoding string) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case Brotli:
+		w = brotli.NewWriterLevel(&buf, brotliEncodeLevel)
+	case Zstd:
+		return zstdEncoder.EncodeAll(body, nil), nil
+	case Gzip:
+		gz, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		w = gz
+	default:
+		return body, nil
+	}
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
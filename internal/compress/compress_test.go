@@ -0,0 +1,142 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNewWriterRoundTrip(t *testing.T) {
+	bodies := [][]byte{
+		[]byte(`[{"code":"11","value":"Aceh"}]`),
+		bytes.Repeat([]byte(`{"code":"11.01","value":"Kabupaten Aceh Selatan"},`), 1000),
+	}
+	for _, enc := range append([]string{Identity}, Encodings...) {
+		t.Run(codingName(enc), func(t *testing.T) {
+			// Several responses in turn reuse the pooled zstd encoder.
+			for _, body := range bodies {
+				var buf bytes.Buffer
+				w, err := NewWriter(&buf, enc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := w.Write(body); err != nil {
+					t.Fatal(err)
+				}
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+				if got := decode(t, enc, buf.Bytes()); !bytes.Equal(got, body) {
+					t.Fatalf("decoded %d bytes, want the %d bytes written", len(got), len(body))
+				}
+			}
+		})
+	}
+}
+
+func decode(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var r io.Reader = bytes.NewReader(data)
+	switch encoding {
+	case Brotli:
+		r = brotli.NewReader(r)
+	case Zstd:
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dec.Close()
+		r = dec
+	case Gzip:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gz
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decode %s: %v", codingName(encoding), err)
+	}
+	return out
+}
+
+// benchBodies are region lists of the data directory: the states and the
+// villages of one district.
+var benchBodies = []struct{ name, file string }{
+	{"states", "states.json"},
+	{"villages", filepath.Join("villages", "95.06.03.json")},
+}
+
+// codingName names a coding in benchmark names.
+func codingName(encoding string) string {
+	if encoding == Identity {
+		return "identity"
+	}
+	return encoding
+}
+
+func readBenchBody(b *testing.B, file string) []byte {
+	b.Helper()
+	body, err := os.ReadFile(filepath.Join("..", "..", "data", file))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return body
+}
+
+// BenchmarkEncode measures compressing a body once at the high levels used
+// for precompressed lists, reporting the compressed size as B/body.
+func BenchmarkEncode(b *testing.B) {
+	for _, bb := range benchBodies {
+		body := readBenchBody(b, bb.file)
+		for _, enc := range append([]string{Identity}, Encodings...) {
+			b.Run(bb.name+"/"+codingName(enc), func(b *testing.B) {
+				b.ReportAllocs()
+				var size int
+				for range b.N {
+					out, err := Encode(body, enc)
+					if err != nil {
+						b.Fatal(err)
+					}
+					size = len(out)
+				}
+				b.ReportMetric(float64(size), "B/body")
+			})
+		}
+	}
+}
+
+// BenchmarkNewWriter measures compressing a body on the fly, as responses
+// are, reporting the compressed size as B/body.
+func BenchmarkNewWriter(b *testing.B) {
+	for _, bb := range benchBodies {
+		body := readBenchBody(b, bb.file)
+		for _, enc := range append([]string{Identity}, Encodings...) {
+			b.Run(bb.name+"/"+codingName(enc), func(b *testing.B) {
+				b.ReportAllocs()
+				var buf bytes.Buffer
+				for range b.N {
+					buf.Reset()
+					w, err := NewWriter(&buf, enc)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if _, err := w.Write(body); err != nil {
+						b.Fatal(err)
+					}
+					if err := w.Close(); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(buf.Len()), "B/body")
+			})
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// Package config loads the server configuration from an optional YAML file,
+// the environment and command-line flags, and validates it.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ikhsanfalakh/geo-id/internal/logging"
+	"github.com/ikhsanfalakh/geo-id/internal/middleware"
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
+)
+
+// Supported deployment environments.
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Config is the server configuration. Every setting has a key in the config
+// file (its yaml path, such as server.port), an environment variable (its
+// env tag) and a flag named after the variable in lower case with dashes
+// (-port, -rate-limit-anonymous).
+//
+// Durations are given as a number of seconds or as a Go duration ("90s",
+// "24h"); lists as YAML sequences or comma-separated strings.
+type Config struct {
+	App       App       `yaml:"app"`
+	Server    Server    `yaml:"server"`
+	Data      Data      `yaml:"data"`
+	Auth      Auth      `yaml:"auth"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+	Cache     Cache     `yaml:"cache"`
+	Compress  Compress  `yaml:"compress"`
+	CORS      CORS      `yaml:"cors"`
+	Log       Log       `yaml:"log"`
+	Tracing   Tracing   `yaml:"tracing"`
+}
+
+// App describes the running application.
+type App struct {
+	Name    string `yaml:"name" env:"APP_NAME" help:"application name"`
+	Version string `yaml:"version" env:"APP_VERSION" help:"application version"`
+	Env     string `yaml:"env" env:"ENV" help:"environment: development, staging or production"`
+	// BaseURL is the public host, and optionally path, shown in the
+	// Swagger UI. It defaults to localhost and Server.Port.
+	BaseURL string `yaml:"base_url" env:"BASE_URL" help:"public host[/path] used by the Swagger UI (default localhost:PORT)"`
+	Swagger bool   `yaml:"swagger" env:"ENABLE_SWAGGER" help:"serve the Swagger UI at /apidocs"`
+}
+
+// Server configures the listeners and shutdown.
+type Server struct {
+	Port int `yaml:"port" env:"PORT" help:"HTTP port"`
+	// GRPCPort is the gRPC port; 0 disables the gRPC server.
+	GRPCPort int `yaml:"grpc_port" env:"GRPC_PORT" help:"gRPC port (0 disables gRPC)"`
+	// AdminPort serves /metrics; 0 serves it on Port instead.
+	AdminPort       int           `yaml:"admin_port" env:"ADMIN_PORT" help:"admin port for /metrics (0 serves it on PORT)"`
+	ShutdownDelay   time.Duration `yaml:"shutdown_delay" env:"SHUTDOWN_DELAY" help:"time to keep serving after readiness fails on shutdown"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" help:"time to wait for in-flight requests on shutdown"`
+}
+
+// Data locates the dataset.
+type Data struct {
+	Dir string `yaml:"dir" env:"DATA_DIR" help:"dataset directory"`
+	// PastDirs hold past editions of the dataset, newest first, against
+	// which NIK region prefixes that no longer exist are checked.
+	PastDirs []string `yaml:"past_dirs" env:"PAST_DATA_DIRS" help:"comma-separated directories of past dataset editions, newest first"`
+}
+
+// Auth configures API keys.
+type Auth struct {
+	APIKeys []string `yaml:"api_keys" env:"API_KEYS" secret:"true" help:"comma-separated API keys"`
+	// KeysFile is a YAML file of API keys with their owner, rate limit,
+	// allowed origins and validity, used alongside APIKeys.
+	KeysFile string `yaml:"keys_file" env:"API_KEYS_FILE" help:"YAML file of API keys with metadata"`
+}
+
+// Rate limiter stores.
+const (
+	// LimiterMemory keeps the windows of each process in memory.
+	LimiterMemory = "memory"
+	// LimiterRedis shares the windows of every replica in Redis.
+	LimiterRedis = "redis"
+)
+
+// Rate limit algorithms of the memory store.
+const (
+	// AlgorithmSlidingLog records every request: exact, but its memory and
+	// check time grow with the limit.
+	AlgorithmSlidingLog = "sliding_log"
+	// AlgorithmSlidingWindow estimates the sliding window from two fixed
+	// window counters.
+	AlgorithmSlidingWindow = "sliding_window"
+	// AlgorithmGCRA is the generic cell rate algorithm, a token bucket.
+	AlgorithmGCRA = "gcra"
+)
+
+// RateLimit sets the per-minute request limits of each tier, the algorithm
+// enforcing them and where the windows are kept.
+type RateLimit struct {
+	Anonymous int    `yaml:"anonymous" env:"RATE_LIMIT_ANONYMOUS" help:"requests per minute per client IP"`
+	APIKey    int    `yaml:"api_key" env:"RATE_LIMIT_API_KEY" help:"requests per minute per API key"`
+	Algorithm string `yaml:"algorithm" env:"RATE_LIMIT_ALGORITHM" help:"rate limit algorithm: sliding_log, sliding_window or gcra"`
+	Store     string `yaml:"store" env:"RATE_LIMIT_STORE" help:"where rate limit windows are kept: memory or redis"`
+	// RedisURL is a redis:// or rediss:// URL, which may carry the
+	// password, database and timeouts.
+	RedisURL    string `yaml:"redis_url" env:"RATE_LIMIT_REDIS_URL" secret:"true" help:"Redis URL of the redis store"`
+	RedisPrefix string `yaml:"redis_prefix" env:"RATE_LIMIT_REDIS_PREFIX" help:"prefix of the Redis keys of the redis store"`
+	// FailOpen allows requests when the store fails; otherwise they are
+	// refused with 503.
+	FailOpen bool `yaml:"fail_open" env:"RATE_LIMIT_FAIL_OPEN" help:"allow requests when the rate limit store is unavailable"`
+}
+
+// Cache sets the Cache-Control max-age per route group; 0 sends no-cache.
+type Cache struct {
+	MaxAgeRegions time.Duration `yaml:"max_age_regions" env:"CACHE_MAX_AGE_REGIONS" help:"Cache-Control max-age of region routes"`
+	MaxAgeExport  time.Duration `yaml:"max_age_export" env:"CACHE_MAX_AGE_EXPORT" help:"Cache-Control max-age of /export"`
+}
+
+// Compress configures response compression.
+type Compress struct {
+	MinSize     int  `yaml:"min_size" env:"COMPRESS_MIN_SIZE" help:"smallest response body, in bytes, that is compressed"`
+	Precompress bool `yaml:"precompress" env:"PRECOMPRESS" help:"compress the full region lists at startup"`
+}
+
+// CORS configures cross-origin requests; no allowed origins disables CORS.
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" help:"origins allowed to call the API (*. for subdomains)"`
+	AllowedMethods   []string      `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS" help:"methods allowed in preflight responses"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS" help:"request headers allowed in preflight responses"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" help:"allow cookies and HTTP authentication"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" help:"how long browsers may cache preflight responses"`
+}
+
+// Middleware returns the CORS middleware configuration.
+func (c CORS) Middleware() middleware.CORSConfig {
+	return middleware.CORSConfig{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowedMethods:   c.AllowedMethods,
+		AllowedHeaders:   c.AllowedHeaders,
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           c.MaxAge,
+	}
+}
+
+// Log configures logging.
+type Log struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" help:"minimum log level: debug, info, warn or error"`
+	Format string `yaml:"format" env:"LOG_FORMAT" help:"log encoding: text or json"`
+	Access bool   `yaml:"access" env:"ACCESS_LOG" help:"log one record per request"`
+}
+
+// Tracing configures the span exporter. The sampler and the OTLP endpoint
+// are read by OpenTelemetry from the standard OTEL_* variables.
+type Tracing struct {
+	Exporter string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER" help:"trace exporter: none, stdout, file or otlp"`
+	File     string `yaml:"file" env:"TRACES_FILE" help:"output file of the file trace exporter"`
+}
+
+// Default returns the configuration used for settings that are not set.
+func Default() *Config {
+	return &Config{
+		App: App{
+			Name:    "Geo-ID API",
+			Version: "1.0",
+			Env:     EnvDevelopment,
+			Swagger: true,
+		},
+		Server: Server{
+			Port:            8080,
+			GRPCPort:        9090,
+			AdminPort:       9091,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Data: Data{Dir: "data"},
+		RateLimit: RateLimit{
+			Anonymous:   middleware.DefaultLimitAnonymous,
+			APIKey:      middleware.DefaultLimitAPIKey,
+			Algorithm:   AlgorithmSlidingLog,
+			Store:       LimiterMemory,
+			RedisPrefix: "geo-id:ratelimit:",
+			FailOpen:    true,
+		},
+		Cache: Cache{
+			MaxAgeRegions: 24 * time.Hour,
+			MaxAgeExport:  24 * time.Hour,
+		},
+		Compress: Compress{MinSize: 1024, Precompress: true},
+		CORS:     CORS{MaxAge: 10 * time.Minute},
+		Log:      Log{Level: "info", Format: logging.FormatText, Access: true},
+		Tracing:  Tracing{Exporter: tracing.ExporterNone, File: "traces.jsonl"},
+	}
+}
+
+// Validate reports every invalid setting, each named by its file key and
+// environment variable.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(setting any, ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", c.name(setting), fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(&c.App.Name, c.App.Name != "", "must not be empty")
+	switch c.App.Env {
+	case EnvDevelopment, EnvStaging, EnvProduction:
+	default:
+		check(&c.App.Env, false, "must be development, staging or production, got %q", c.App.Env)
+	}
+
+	check(&c.Server.Port, c.Server.Port >= 1 && c.Server.Port <= 65535,
+		"must be a port between 1 and 65535, got %d", c.Server.Port)
+	check(&c.Server.GRPCPort, c.Server.GRPCPort >= 0 && c.Server.GRPCPort <= 65535,
+		"must be a port between 1 and 65535 or 0 to disable gRPC, got %d", c.Server.GRPCPort)
+	check(&c.Server.AdminPort, c.Server.AdminPort >= 0 && c.Server.AdminPort <= 65535,
+		"must be a port between 1 and 65535 or 0 to use PORT, got %d", c.Server.AdminPort)
+	check(&c.Server.GRPCPort, c.Server.GRPCPort == 0 || c.Server.GRPCPort != c.Server.Port,
+		"must differ from the HTTP port %d", c.Server.Port)
+	check(&c.Server.AdminPort, c.Server.AdminPort == 0 ||
+		(c.Server.AdminPort != c.Server.Port && c.Server.AdminPort != c.Server.GRPCPort),
+		"must differ from the HTTP and gRPC ports")
+	check(&c.Server.ShutdownTimeout, c.Server.ShutdownTimeout > 0, "must be positive")
+
+	check(&c.Data.Dir, c.Data.Dir != "", "must not be empty")
+	for _, dir := range c.Data.PastDirs {
+		check(&c.Data.PastDirs, dir != "" && dir != c.Data.Dir, "must not be empty or the current data directory")
+	}
+	for _, key := range c.Auth.APIKeys {
+		check(&c.Auth.APIKeys, key != "" && strings.TrimSpace(key) == key, "keys must not be empty or padded with spaces")
+	}
+
+	check(&c.RateLimit.Anonymous, c.RateLimit.Anonymous > 0, "must be positive, got %d", c.RateLimit.Anonymous)
+	check(&c.RateLimit.APIKey, c.RateLimit.APIKey > 0, "must be positive, got %d", c.RateLimit.APIKey)
+	switch strings.ToLower(c.RateLimit.Algorithm) {
+	case AlgorithmSlidingLog, AlgorithmSlidingWindow, AlgorithmGCRA:
+	default:
+		check(&c.RateLimit.Algorithm, false, "must be sliding_log, sliding_window or gcra, got %q", c.RateLimit.Algorithm)
+	}
+	switch strings.ToLower(c.RateLimit.Store) {
+	case LimiterMemory:
+	case LimiterRedis:
+		check(&c.RateLimit.RedisURL, c.RateLimit.RedisURL != "", "must be set for the redis store")
+		check(&c.RateLimit.Algorithm, strings.ToLower(c.RateLimit.Algorithm) == AlgorithmSlidingLog,
+			"must be sliding_log for the redis store, got %q", c.RateLimit.Algorithm)
+	default:
+		check(&c.RateLimit.Store, false, "must be memory or redis, got %q", c.RateLimit.Store)
+	}
+	check(&c.Compress.MinSize, c.Compress.MinSize >= 0, "must not be negative, got %d", c.Compress.MinSize)
+
+	if len(c.CORS.AllowedOrigins) > 0 {
+		if err := c.CORS.Middleware().Validate(); err != nil {
+			check(&c.CORS.AllowedOrigins, false, "%v", err)
+		}
+	}
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		check(&c.Log.Level, false, "must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case logging.FormatText, logging.FormatJSON:
+	default:
+		check(&c.Log.Format, false, "must be text or json, got %q", c.Log.Format)
+	}
+	switch strings.ToLower(c.Tracing.Exporter) {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	case tracing.ExporterFile:
+		check(&c.Tracing.File, c.Tracing.File != "", "must be set for the file exporter")
+	default:
+		check(&c.Tracing.Exporter, false, "must be none, stdout, file or otlp, got %q", c.Tracing.Exporter)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// name returns the file key and environment variable of the setting that
+// ptr points to, such as "server.port (PORT)".
+func (c *Config) name(ptr any) string {
+	for _, f := range c.fields() {
+		if f.value.Addr().Interface() == ptr {
+			return f.path + " (" + f.env + ")"
+		}
+	}
+	return "unknown setting"
+}
@@ -0,0 +1,373 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvFile is the environment variable naming the config file when the
+// -config flag is not given.
+const EnvFile = "CONFIG_FILE"
+
+// ErrFlags wraps errors parsing the command line, which the flag set has
+// already reported along with its usage.
+var ErrFlags = errors.New("invalid flags")
+
+// redacted replaces secret values in Show.
+const redacted = "REDACTED"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// field is a single setting of a Config.
+type field struct {
+	path   string // key in the config file, e.g. "server.port"
+	env    string
+	help   string
+	secret bool
+	value  reflect.Value
+}
+
+// flagName returns the command-line flag of the setting.
+func (f field) flagName() string {
+	return strings.ToLower(strings.ReplaceAll(f.env, "_", "-"))
+}
+
+// fields lists the settings of c in declaration order.
+func (c *Config) fields() []field {
+	var fields []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			path := prefix + sf.Tag.Get("yaml")
+			if sf.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), path+".")
+				continue
+			}
+			fields = append(fields, field{
+				path:   path,
+				env:    sf.Tag.Get("env"),
+				help:   sf.Tag.Get("help"),
+				secret: sf.Tag.Get("secret") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return fields
+}
+
+// Load registers the -config flag and a flag per setting on fs, parses args
+// with it, and returns the validated configuration. Each layer overrides
+// the ones before it:
+//  1. Default values.
+//  2. The YAML file named by -config or CONFIG_FILE, if any.
+//  3. Environment variables; empty ones are ignored.
+//  4. Flags.
+//
+// Callers may register their own flags on fs first and read the remaining
+// arguments from fs.Args. Load returns flag.ErrHelp when args ask for help
+// and ErrFlags when they cannot be parsed.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+	flags := Default()
+	byFlag := map[string]field{}
+	for _, f := range flags.fields() {
+		byFlag[f.flagName()] = f
+		fs.Var(flagValue{f.value}, f.flagName(), f.help+" (env "+f.env+")")
+	}
+	file := fs.String("config", "", "YAML config file (env "+EnvFile+")")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrFlags, err)
+	}
+
+	if *file == "" {
+		*file = os.Getenv(EnvFile)
+	}
+	if *file != "" {
+		if err := cfg.readFile(*file); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, f := range cfg.fields() {
+		if raw := os.Getenv(f.env); raw != "" {
+			if err := set(f.value, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid environment:\n%w", errors.Join(errs...))
+	}
+
+	fields := cfg.fields()
+	index := map[string]int{}
+	for i, f := range fields {
+		index[f.flagName()] = i
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		if from, ok := byFlag[fl.Name]; ok {
+			fields[index[fl.Name]].value.Set(from.value)
+		}
+	})
+
+	if cfg.App.BaseURL == "" {
+		cfg.App.BaseURL = "localhost:" + strconv.Itoa(cfg.Server.Port)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// IsFlag reports whether name is one of the flags registered by Load.
+func IsFlag(name string) bool {
+	if name == "config" {
+		return true
+	}
+	for _, f := range Default().fields() {
+		if f.flagName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readFile overrides c with the settings in the YAML file at path. Unknown
+// keys are rejected so that typos are not silently ignored.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	byPath := map[string]field{}
+	for _, f := range c.fields() {
+		byPath[f.path] = f
+	}
+	var errs []error
+	var walk func(node *yaml.Node, prefix string)
+	walk = func(node *yaml.Node, prefix string) {
+		if node.Kind != yaml.MappingNode {
+			errs = append(errs, fmt.Errorf("line %d: %s must be a mapping", node.Line, strings.TrimSuffix(prefix, ".")))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			path := prefix + key.Value
+			f, ok := byPath[path]
+			switch {
+			case ok:
+				if err := setNode(f.value, value); err != nil {
+					errs = append(errs, fmt.Errorf("line %d: %s: %w", value.Line, path, err))
+				}
+			case isSection(byPath, path):
+				walk(value, path+".")
+			default:
+				errs = append(errs, fmt.Errorf("line %d: unknown setting %s", key.Line, path))
+			}
+		}
+	}
+	walk(doc.Content[0], "")
+	if len(errs) > 0 {
+		return fmt.Errorf("%s:\n%w", path, errors.Join(errs...))
+	}
+	return nil
+}
+
+// isSection reports whether path is a prefix of some setting's path.
+func isSection(byPath map[string]field, path string) bool {
+	for p := range byPath {
+		if strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// setNode sets v from a YAML scalar, or from a sequence for lists.
+func setNode(v reflect.Value, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return nil
+		}
+		return set(v, node.Value)
+	case yaml.SequenceNode:
+		if v.Type() != reflect.TypeOf([]string(nil)) {
+			return errors.New("must not be a list")
+		}
+		list := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: list items must be strings", item.Line)
+			}
+			list = append(list, item.Value)
+		}
+		v.Set(reflect.ValueOf(list))
+		return nil
+	}
+	return errors.New("must be a single value or a list")
+}
+
+// set parses raw into v according to v's type.
+func set(v reflect.Value, raw string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := parseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q: must be true or false", raw)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// parseDuration parses a number of seconds or a Go duration string.
+func parseDuration(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	var d time.Duration
+	if n, err := strconv.Atoi(raw); err == nil {
+		d = time.Duration(n) * time.Second
+	} else if d, err = time.ParseDuration(raw); err != nil {
+		return 0, fmt.Errorf("invalid duration %q: want seconds or a duration such as 90s", raw)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid duration %q: must not be negative", raw)
+	}
+	return d, nil
+}
+
+// format renders v as it is written in the environment or a flag.
+func format(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// flagValue binds a flag to a setting.
+type flagValue struct{ v reflect.Value }
+
+func (f flagValue) String() string {
+	if !f.v.IsValid() {
+		return ""
+	}
+	return format(f.v)
+}
+
+func (f flagValue) Set(raw string) error { return set(f.v, raw) }
+
+func (f flagValue) IsBoolFlag() bool { return f.v.IsValid() && f.v.Kind() == reflect.Bool }
+
+// Show writes c to w as a YAML config file, with each setting's
+// environment variable as a comment and secrets replaced by REDACTED.
+func (c *Config) Show(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := map[string]*yaml.Node{}
+	for _, f := range c.fields() {
+		parent := root
+		key := f.path
+		if i := strings.LastIndexByte(f.path, '.'); i != -1 {
+			section, ok := sections[f.path[:i]]
+			if !ok {
+				section = &yaml.Node{Kind: yaml.MappingNode}
+				sections[f.path[:i]] = section
+				root.Content = append(root.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Value: f.path[:i]}, section)
+			}
+			parent, key = section, f.path[i+1:]
+		}
+		value := valueNode(f)
+		value.LineComment = f.env
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// valueNode returns the YAML node of f's value.
+func valueNode(f field) *yaml.Node {
+	if f.value.Kind() == reflect.Slice {
+		list := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, item := range f.value.Interface().([]string) {
+			if f.secret {
+				item = redacted
+			}
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+		}
+		return list
+	}
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: format(f.value)}
+	switch {
+	case f.secret && f.value.String() != "":
+		node.Value = redacted
+	case f.value.Kind() == reflect.Int:
+		node.Tag = "!!int"
+	case f.value.Kind() == reflect.Bool:
+		node.Tag = "!!bool"
+	default:
+		node.Tag = "!!str"
+	}
+	return node
+}
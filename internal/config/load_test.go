@@ -0,0 +1,311 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv hides the environment of the test process from Load: empty
+// variables are ignored.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv(EnvFile, "")
+	for _, f := range Default().fields() {
+		t.Setenv(f.env, "")
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func load(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("geo-id", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return Load(fs, args)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	file := writeFile(t, `
+server:
+  port: 8000
+  shutdown_timeout: 10
+rate_limit:
+  anonymous: 100
+  api_key: 2000
+cache:
+  max_age_export: 3600
+data:
+  past_dirs: [data-2022, data-2019]
+log:
+  level: debug
+`)
+	t.Setenv("RATE_LIMIT_API_KEY", "3000")
+	t.Setenv("CACHE_MAX_AGE_EXPORT", "90s")
+	t.Setenv("PORT", "8001")
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("ENABLE_SWAGGER", "false")
+
+	cfg, err := load(t, "-config", file, "-port", "8002", "-log-format", "text", "-cors-allowed-origins", "https://a.example, https://b.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		layer string
+		got   any
+		want  any
+	}{
+		{"default", cfg.Server.GRPCPort, 9090},
+		{"default", cfg.Cache.MaxAgeRegions, 24 * time.Hour},
+		{"file", cfg.RateLimit.Anonymous, 100},
+		{"file", cfg.Log.Level, "debug"},
+		{"file seconds", cfg.Server.ShutdownTimeout, 10 * time.Second},
+		{"file list", cfg.Data.PastDirs, []string{"data-2022", "data-2019"}},
+		{"env over file", cfg.RateLimit.APIKey, 3000},
+		{"env duration over file", cfg.Cache.MaxAgeExport, 90 * time.Second},
+		{"env over default", cfg.App.Swagger, false},
+		{"flag over env and file", cfg.Server.Port, 8002},
+		{"flag over env", cfg.Log.Format, "text"},
+		{"flag list", cfg.CORS.AllowedOrigins, []string{"https://a.example", "https://b.example"}},
+		{"derived from the port", cfg.App.BaseURL, "localhost:8002"},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.layer, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvFile, writeFile(t, "server:\n  port: 8000\n"))
+
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Port != 8000 {
+		t.Fatalf("got port %d, want 8000 from %s", cfg.Server.Port, EnvFile)
+	}
+
+	// -config takes precedence over CONFIG_FILE.
+	cfg, err = load(t, "-config", writeFile(t, "server:\n  port: 8001\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Port != 8001 {
+		t.Fatalf("got port %d, want 8001 from -config", cfg.Server.Port)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t)
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Default()
+	want.App.BaseURL = "localhost:8080"
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %+v, want the defaults %+v", cfg, want)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "unknown keys",
+			content: "server:\n  prot: 8000\nratelimit:\n  anonymous: 10\n",
+			want:    []string{"line 2: unknown setting server.prot", "line 3: unknown setting ratelimit"},
+		},
+		{
+			name:    "section that is a value",
+			content: "server: 8000\n",
+			want:    []string{"line 1: server must be a mapping"},
+		},
+		{
+			name:    "list for a single value",
+			content: "server:\n  port: [8000]\n",
+			want:    []string{"line 2: server.port: must not be a list"},
+		},
+		{
+			name:    "invalid values",
+			content: "server:\n  port: eighty\n  shutdown_timeout: soon\nlog:\n  access: maybe\n",
+			want: []string{
+				`line 2: server.port: invalid integer "eighty"`,
+				`line 3: server.shutdown_timeout: invalid duration "soon"`,
+				`line 5: log.access: invalid boolean "maybe"`,
+			},
+		},
+		{
+			name:    "invalid YAML",
+			content: "server:\n  port: [\n",
+			want:    []string{"config.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			_, err := load(t, "-config", writeFile(t, tt.content))
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("got %q, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+
+	clearEnv(t)
+	if _, err := load(t, "-config", filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: got %v, want os.ErrNotExist", err)
+	}
+	if cfg, err := load(t, "-config", writeFile(t, "# nothing set\n")); err != nil || cfg.Server.Port != 8080 {
+		t.Errorf("empty file: got %v, want the defaults", err)
+	}
+	if cfg, err := load(t, "-config", writeFile(t, "data:\n  dir: ~\n")); err != nil || cfg.Data.Dir != "data" {
+		t.Errorf("null value: got %v, want the default kept", err)
+	}
+}
+
+func TestLoadEnvAndFlagErrors(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("PORT", "http")
+	t.Setenv("SHUTDOWN_TIMEOUT", "-5")
+	_, err := load(t)
+	for _, want := range []string{`PORT: invalid integer "http"`, `SHUTDOWN_TIMEOUT: invalid duration "-5": must not be negative`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("got %v, want it to mention %q", err, want)
+		}
+	}
+
+	clearEnv(t)
+	if _, err := load(t, "-port", "http"); !errors.Is(err, ErrFlags) {
+		t.Errorf("invalid flag: got %v, want ErrFlags", err)
+	}
+	if _, err := load(t, "-no-such-flag"); !errors.Is(err, ErrFlags) {
+		t.Errorf("unknown flag: got %v, want ErrFlags", err)
+	}
+	if _, err := load(t, "-h"); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("help: got %v, want flag.ErrHelp", err)
+	}
+	if _, err := load(t, "-port", "0"); err == nil || !strings.Contains(err.Error(), "PORT") {
+		t.Errorf("invalid port: got %v, want a validation error naming PORT", err)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{raw: "90", want: 90 * time.Second},
+		{raw: " 15 ", want: 15 * time.Second},
+		{raw: "0", want: 0},
+		{raw: "90s", want: 90 * time.Second},
+		{raw: "1h30m", want: 90 * time.Minute},
+		{raw: "250ms", want: 250 * time.Millisecond},
+		{raw: "-1", wantErr: true},
+		{raw: "-5s", wantErr: true},
+		{raw: "1.5", wantErr: true},
+		{raw: "soon", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.raw)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, %v; want %v, error %v", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestShowRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.APIKeys = []string{"key-one", "key-two"}
+	cfg.RateLimit.RedisURL = "redis://:hunter2@redis:6379/0"
+	cfg.Data.PastDirs = []string{"data-2022"}
+
+	var buf bytes.Buffer
+	if err := cfg.Show(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, secret := range []string{"key-one", "key-two", "hunter2"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("output shows %q:\n%s", secret, out)
+		}
+	}
+	for _, want := range []string{
+		"api_keys: [REDACTED, REDACTED] # API_KEYS",
+		"redis_url: REDACTED # RATE_LIMIT_REDIS_URL",
+		"past_dirs: [data-2022] # PAST_DATA_DIRS",
+		"port: 8080 # PORT",
+		"max_age_regions: 24h0m0s # CACHE_MAX_AGE_REGIONS",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	// Unset secrets are shown empty rather than redacted.
+	buf.Reset()
+	if err := Default().Show(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `redis_url: "" # RATE_LIMIT_REDIS_URL`) || strings.Contains(buf.String(), redacted) {
+		t.Errorf("output redacts unset secrets:\n%s", buf.String())
+	}
+}
+
+func TestShowRoundTrip(t *testing.T) {
+	clearEnv(t)
+	want := Default()
+	want.App.BaseURL = "geo.example/api"
+	want.Server.ShutdownDelay = 5 * time.Second
+	want.Data.PastDirs = []string{"data-2022", "data-2019"}
+	want.Log.Access = false
+
+	var buf bytes.Buffer
+	if err := want.Show(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := load(t, "-config", writeFile(t, buf.String()))
+	if err != nil {
+		t.Fatalf("loading the shown config: %v\n%s", err, buf.String())
+	}
+	// Empty lists load as empty rather than nil slices, so the
+	// configurations are compared as shown.
+	var again bytes.Buffer
+	if err := got.Show(&again); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != buf.String() {
+		t.Fatalf("got\n%s\nwant\n%s", again.String(), buf.String())
+	}
+	if !slices.Equal(got.Data.PastDirs, want.Data.PastDirs) || got.Server.ShutdownDelay != want.Server.ShutdownDelay || got.Log.Access {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
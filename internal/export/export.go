@@ -0,0 +1,256 @@
+// Package export streams the flattened region hierarchy as CSV, NDJSON or a
+// JSON array. It walks the dataset one parent at a time, so memory use does
+// not grow with the size of the export.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
+)
+
+// tracer records export spans.
+var tracer = otel.Tracer("github.com/ikhsanfalakh/geo-id/internal/export")
+
+// Supported output formats.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatJSON   = "json"
+)
+
+// csvHeader is the column layout of CSV exports.
+var csvHeader = []string{
+	"code", "name", "level", "type",
+	"state_code", "state_name",
+	"city_code", "city_name",
+	"district_code", "district_name",
+}
+
+// Options selects what is exported and how.
+type Options struct {
+	// Format is one of FormatCSV, FormatNDJSON or FormatJSON.
+	Format string
+	// Level, when set, restricts the export to regions of that level.
+	Level service.Level
+	// Within, when set, restricts the export to the region with this code
+	// and its descendants.
+	Within string
+}
+
+// Record is one exported region together with the codes and names of its
+// ancestors, ordered from the state down.
+type Record struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Level       string   `json:"level"`
+	Type        string   `json:"type"`
+	ParentCodes []string `json:"parent_codes"`
+	ParentNames []string `json:"parent_names"`
+}
+
+// ContentType returns the MIME type of the given format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json"
+	}
+}
+
+// Validate checks the options before any output is written, so callers can
+// still report errors with a proper status code.
+func (o Options) Validate(ctx context.Context, svc *service.LocationService) error {
+	switch o.Format {
+	case FormatCSV, FormatNDJSON, FormatJSON:
+	default:
+		return fmt.Errorf("%w: format must be one of csv, ndjson or json", service.ErrInvalidQuery)
+	}
+	switch o.Level {
+	case "", service.LevelState, service.LevelCity, service.LevelDistrict, service.LevelVillage:
+	default:
+		return fmt.Errorf("%w: level must be one of state, city, district or village", service.ErrInvalidQuery)
+	}
+	if o.Within != "" {
+		if _, _, err := svc.GetRegion(ctx, o.Within); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write streams the export described by opts to w.
+func Write(ctx context.Context, w io.Writer, svc *service.LocationService, opts Options) error {
+	if err := opts.Validate(ctx, svc); err != nil {
+		return err
+	}
+	enc := newEncoder(w, opts.Format)
+	if err := enc.begin(); err != nil {
+		return err
+	}
+	if err := Walk(ctx, svc, opts, enc.write); err != nil {
+		return err
+	}
+	return enc.end()
+}
+
+// Walk calls fn for every region selected by opts' Level and Within, in
+// depth-first code order. Options.Format is ignored. Walking stops at the
+// first error returned by fn. The walk is recorded as a single span; the
+// service calls it makes are not traced.
+func Walk(ctx context.Context, svc *service.LocationService, opts Options, fn func(Record) error) (err error) {
+	ctx, span := tracer.Start(ctx, "export.Walk", trace.WithAttributes(
+		tracing.String("geoid.level", string(opts.Level)),
+		tracing.String("geoid.within", opts.Within),
+	))
+	defer func() { tracing.End(span, err) }()
+	return walkFrom(tracing.Suppress(ctx), svc, opts, fn)
+}
+
+func walkFrom(ctx context.Context, svc *service.LocationService, opts Options, fn func(Record) error) error {
+	if opts.Within == "" {
+		states, err := svc.GetStates(ctx)
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			if err := walk(ctx, svc, fn, opts.Level, state, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	root, _, err := svc.GetRegion(ctx, opts.Within)
+	if err != nil {
+		return err
+	}
+	ancestors, err := svc.Ancestors(ctx, opts.Within)
+	if err != nil {
+		return err
+	}
+	return walk(ctx, svc, fn, opts.Level, *root, ancestors)
+}
+
+// walk emits region and its descendants depth-first. Only the children of
+// the regions on the current path are held in memory.
+func walk(ctx context.Context, svc *service.LocationService, fn func(Record) error, only service.Level, region model.Region, ancestors []model.Region) error {
+	level, err := service.LevelOf(region.Code)
+	if err != nil {
+		return err
+	}
+	if only == "" || only == level {
+		if err := fn(newRecord(region, level, ancestors)); err != nil {
+			return err
+		}
+	}
+	if level == only || level == service.LevelVillage {
+		return nil
+	}
+
+	children, err := svc.GetChildren(ctx, region.Code)
+	if err != nil {
+		return err
+	}
+	path := append(ancestors[:len(ancestors):len(ancestors)], region)
+	for _, child := range children {
+		if err := walk(ctx, svc, fn, only, child, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newRecord(region model.Region, level service.Level, ancestors []model.Region) Record {
+	rec := Record{
+		Code:        region.Code,
+		Name:        region.Value,
+		Level:       string(level),
+		Type:        service.RegionType(region),
+		ParentCodes: make([]string, len(ancestors)),
+		ParentNames: make([]string, len(ancestors)),
+	}
+	for i, a := range ancestors {
+		rec.ParentCodes[i] = a.Code
+		rec.ParentNames[i] = a.Value
+	}
+	return rec
+}
+
+// encoder writes records in one of the supported formats.
+type encoder struct {
+	w      io.Writer
+	format string
+	csv    *csv.Writer
+	json   *json.Encoder
+	count  int
+}
+
+func newEncoder(w io.Writer, format string) *encoder {
+	enc := &encoder{w: w, format: format}
+	switch format {
+	case FormatCSV:
+		enc.csv = csv.NewWriter(w)
+	default:
+		enc.json = json.NewEncoder(w)
+		enc.json.SetEscapeHTML(false)
+	}
+	return enc
+}
+
+func (e *encoder) begin() error {
+	switch e.format {
+	case FormatCSV:
+		return e.csv.Write(csvHeader)
+	case FormatJSON:
+		_, err := io.WriteString(e.w, "[\n")
+		return err
+	}
+	return nil
+}
+
+func (e *encoder) write(rec Record) error {
+	defer func() { e.count++ }()
+	switch e.format {
+	case FormatCSV:
+		row := []string{rec.Code, rec.Name, rec.Level, rec.Type, "", "", "", "", "", ""}
+		for i := range rec.ParentCodes {
+			row[4+2*i] = rec.ParentCodes[i]
+			row[5+2*i] = rec.ParentNames[i]
+		}
+		return e.csv.Write(row)
+	case FormatJSON:
+		if e.count > 0 {
+			if _, err := io.WriteString(e.w, ","); err != nil {
+				return err
+			}
+		}
+	}
+	// json.Encoder terminates every value with a newline, which is exactly
+	// the NDJSON framing and keeps JSON array output readable.
+	return e.json.Encode(rec)
+}
+
+func (e *encoder) end() error {
+	switch e.format {
+	case FormatCSV:
+		e.csv.Flush()
+		return e.csv.Error()
+	case FormatJSON:
+		_, err := io.WriteString(e.w, "]\n")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package gql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+const (
+	// MaxDepth is the deepest field nesting a query may use. Depth 5 is
+	// enough to go from a state down to the names of its villages.
+	MaxDepth = 5
+	// MaxComplexity is the highest estimated cost a query may have.
+	MaxComplexity = 5000
+)
+
+// ErrQueryTooComplex is returned for queries exceeding MaxDepth or MaxComplexity.
+var ErrQueryTooComplex = errors.New("query too complex")
+
+// listSizes estimates how many items each list field returns when no limit
+// argument is given, and is used to multiply the cost of its selections.
+var listSizes = map[string]int{
+	"states":    38,
+	"children":  defaultChildrenLimit,
+	"ancestors": 3,
+	"search":    defaultSearchLimit,
+}
+
+// Cost is the result of analysing a query before execution.
+type Cost struct {
+	Depth      int
+	Complexity int
+}
+
+// Analyze parses query and estimates its depth and complexity. Every field
+// costs 1, and the selections below a list field are multiplied by its limit
+// argument (or its typical size), so a query walking several levels of
+// children is priced by how many regions it could return. Introspection
+// fields are not counted.
+func Analyze(query string, variables map[string]interface{}) (Cost, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(query)})})
+	if err != nil {
+		return Cost{}, err
+	}
+
+	a := &analyzer{variables: variables, fragments: map[string]*ast.FragmentDefinition{}}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			a.fragments[frag.Name.Value] = frag
+		}
+	}
+
+	var total Cost
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		complexity, depth := a.selectionSet(op.SelectionSet, 1, map[string]bool{})
+		if depth > total.Depth {
+			total.Depth = depth
+		}
+		total.Complexity += complexity
+	}
+
+	if total.Depth > MaxDepth {
+		return total, fmt.Errorf("%w: depth %d exceeds the maximum of %d", ErrQueryTooComplex, total.Depth, MaxDepth)
+	}
+	if total.Complexity > MaxComplexity {
+		return total, fmt.Errorf("%w: complexity %d exceeds the maximum of %d", ErrQueryTooComplex, total.Complexity, MaxComplexity)
+	}
+	return total, nil
+}
+
+type analyzer struct {
+	variables map[string]interface{}
+	fragments map[string]*ast.FragmentDefinition
+}
+
+// selectionSet returns the cost and maximum depth of set, whose fields are
+// at the given depth. visiting guards against fragment cycles.
+func (a *analyzer) selectionSet(set *ast.SelectionSet, depth int, visiting map[string]bool) (int, int) {
+	if set == nil {
+		return 0, depth - 1
+	}
+	cost, maxDepth := 0, depth-1
+	for _, sel := range set.Selections {
+		var c, d int
+		switch s := sel.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			childCost, childDepth := a.selectionSet(s.SelectionSet, depth+1, visiting)
+			c, d = 1+a.listSize(s)*childCost, childDepth
+			if s.SelectionSet == nil {
+				d = depth
+			}
+		case *ast.InlineFragment:
+			c, d = a.selectionSet(s.SelectionSet, depth, visiting)
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			frag, ok := a.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			c, d = a.selectionSet(frag.SelectionSet, depth, visiting)
+			delete(visiting, name)
+		}
+		cost += c
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+	return cost, maxDepth
+}
+
+// listSize returns the multiplier for the selections below field.
+func (a *analyzer) listSize(field *ast.Field) int {
+	size, isList := listSizes[field.Name.Value]
+	if !isList {
+		return 1
+	}
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+				size = n
+			}
+		case *ast.Variable:
+			switch n := a.variables[v.Name.Value].(type) {
+			case float64:
+				if n > 0 {
+					size = int(n)
+				}
+			case int:
+				if n > 0 {
+					size = n
+				}
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,97 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+// resolver implements the schema's field resolvers over the service.
+// Resolvers return model.Region values; a nil interface means null.
+type resolver struct {
+	svc *service.LocationService
+}
+
+// region resolves a code, optionally requiring a specific level. Unknown or
+// malformed codes resolve to null rather than an error.
+func (r *resolver) region(ctx context.Context, code string, level service.Level) (interface{}, error) {
+	region, found, err := r.svc.GetRegion(ctx, code)
+	if err != nil || (level != "" && found != level) {
+		return nil, nil
+	}
+	return *region, nil
+}
+
+func (r *resolver) states(p graphql.ResolveParams) (interface{}, error) {
+	states, err := r.svc.GetStates(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	q, _ := p.Args["q"].(string)
+	result, err := service.ListQuery{Query: q}.Apply(states)
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+func (r *resolver) parent(p graphql.ResolveParams) (interface{}, error) {
+	parent := service.ParentCode(p.Source.(model.Region).Code)
+	if parent == "" {
+		return nil, nil
+	}
+	return r.region(p.Context, parent, "")
+}
+
+// filteredChildren returns the children of the source region, restricted to
+// the type argument when given.
+func (r *resolver) filteredChildren(p graphql.ResolveParams) ([]model.Region, error) {
+	children, err := r.svc.GetChildren(p.Context, p.Source.(model.Region).Code)
+	if err != nil {
+		return nil, err
+	}
+	kind, _ := p.Args["type"].(string)
+	if kind == "" {
+		return children, nil
+	}
+	filtered := children[:0:0]
+	for _, c := range children {
+		if service.RegionType(c) == kind {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
+}
+
+func (r *resolver) children(p graphql.ResolveParams) (interface{}, error) {
+	children, err := r.filteredChildren(p)
+	if err != nil {
+		return nil, err
+	}
+	q := service.ListQuery{}
+	q.Limit, _ = p.Args["limit"].(int)
+	q.Offset, _ = p.Args["offset"].(int)
+	q.Query, _ = p.Args["q"].(string)
+	if q.Limit < 1 {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", service.ErrInvalidQuery, service.MaxListLimit)
+	}
+	result, err := q.Apply(children)
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+func (r *resolver) search(p graphql.ResolveParams) (interface{}, error) {
+	q, _ := p.Args["q"].(string)
+	level, _ := p.Args["level"].(string)
+	limit, _ := p.Args["limit"].(int)
+	if limit < 1 || limit > maxSearchLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", service.ErrInvalidQuery, maxSearchLimit)
+	}
+	return r.svc.Search(p.Context, q, service.Level(level), limit)
+}
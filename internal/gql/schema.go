@@ -0,0 +1,140 @@
+// Package gql exposes the region hierarchy as a GraphQL schema backed by
+// service.LocationService, together with the depth and complexity analysis
+// used to keep queries from walking the whole dataset.
+package gql
+
+import (
+	"github.com/graphql-go/graphql"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+const (
+	// defaultChildrenLimit is the page size of Region.children when no limit is given.
+	defaultChildrenLimit = 100
+	// defaultSearchLimit is the number of search results when no limit is given.
+	defaultSearchLimit = 20
+	// maxSearchLimit caps the number of search results.
+	maxSearchLimit = 100
+)
+
+// NewSchema builds the GraphQL schema over the given service.
+func NewSchema(svc *service.LocationService) (graphql.Schema, error) {
+	r := &resolver{svc: svc}
+
+	var regionType *graphql.Object
+	regionType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Region",
+		Description: "An administrative region: a province (state), regency or city, district (kecamatan) or village.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"code": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "Administrative code, e.g. 11.01.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(model.Region).Code, nil
+					},
+				},
+				"name": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(model.Region).Value, nil
+					},
+				},
+				"level": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "One of state, city, district or village.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						level, err := service.LevelOf(p.Source.(model.Region).Code)
+						return string(level), err
+					},
+				},
+				"type": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "One of provinsi, kabupaten, kota, kecamatan, desa or kelurahan.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return service.RegionType(p.Source.(model.Region)), nil
+					},
+				},
+				"parent": &graphql.Field{
+					Type:        regionType,
+					Description: "The region directly above; null for states.",
+					Resolve:     r.parent,
+				},
+				"ancestors": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(regionType))),
+					Description: "All regions above, from the state down.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return svc.Ancestors(p.Context, p.Source.(model.Region).Code)
+					},
+				},
+				"children": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(regionType))),
+					Description: "The regions directly below, ordered by code.",
+					Args: graphql.FieldConfigArgument{
+						"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultChildrenLimit},
+						"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+						"type":   &graphql.ArgumentConfig{Type: graphql.String, Description: "Only children of this type, e.g. kota."},
+						"q":      &graphql.ArgumentConfig{Type: graphql.String, Description: "Only children whose name contains this text."},
+					},
+					Resolve: r.children,
+				},
+				"childCount": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Number of regions directly below.",
+					Args: graphql.FieldConfigArgument{
+						"type": &graphql.ArgumentConfig{Type: graphql.String, Description: "Only count children of this type."},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						children, err := r.filteredChildren(p)
+						return len(children), err
+					},
+				},
+			}
+		}),
+	})
+
+	codeArgs := graphql.FieldConfigArgument{
+		"code": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+	}
+	byCode := func(level service.Level) *graphql.Field {
+		return &graphql.Field{
+			Type: regionType,
+			Args: codeArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return r.region(p.Context, p.Args["code"].(string), level)
+			},
+		}
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"states": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(regionType))),
+				Args: graphql.FieldConfigArgument{
+					"q": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.states,
+			},
+			"state":    byCode(service.LevelState),
+			"city":     byCode(service.LevelCity),
+			"district": byCode(service.LevelDistrict),
+			"village":  byCode(service.LevelVillage),
+			"region":   byCode(""),
+			"search": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(regionType))),
+				Description: "Regions of any level whose name contains q, ordered by code.",
+				Args: graphql.FieldConfigArgument{
+					"q":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"level": &graphql.ArgumentConfig{Type: graphql.String},
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultSearchLimit},
+				},
+				Resolve: r.search,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
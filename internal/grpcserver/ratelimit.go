@@ -0,0 +1,107 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	geoidv1 "github.com/ikhsanfalakh/geo-id/api/geoid/v1"
+	"github.com/ikhsanfalakh/geo-id/internal/middleware"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+// metadataAPIKey is the metadata key carrying the API key (gRPC lowercases keys).
+const metadataAPIKey = "x-api-key"
+
+// rateLimiter applies the HTTP API's key validation and rate limits to gRPC calls.
+type rateLimiter struct {
+	cfg *middleware.RateLimitConfig
+}
+
+func (rl *rateLimiter) unary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	cost := 1
+	if batch, ok := req.(*geoidv1.BatchGetRequest); ok {
+		cost = service.BatchCost(len(batch.GetCodes()))
+	}
+	if err := rl.check(ctx, cost); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (rl *rateLimiter) stream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := rl.check(ss.Context(), 1); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// check charges cost requests for the caller identified by the context and
+// sends the rate limit state as header metadata.
+func (rl *rateLimiter) check(ctx context.Context, cost int) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	result, _, err := rl.cfg.Check(ctx, middleware.CheckRequest{
+		APIKey:   first(md, metadataAPIKey),
+		ClientIP: clientIP(ctx, md),
+		Origin:   first(md, "origin"),
+		Cost:     cost,
+	})
+	switch {
+	case errors.Is(err, middleware.ErrInvalidAPIKey):
+		return status.Error(codes.Unauthenticated, "invalid API key")
+	case errors.Is(err, middleware.ErrAPIKeyExpired):
+		return status.Error(codes.Unauthenticated, "API key expired")
+	case errors.Is(err, middleware.ErrAPIKeyDisabled):
+		return status.Error(codes.PermissionDenied, "API key disabled")
+	case errors.Is(err, middleware.ErrOriginNotAllowed):
+		return status.Error(codes.PermissionDenied, "origin not allowed for this API key")
+	case errors.Is(err, middleware.ErrCostExceedsLimit):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, middleware.ErrLimiterUnavailable):
+		return status.Error(codes.Unavailable, "rate limiter unavailable")
+	case result.Limit == 0:
+		// The limiter failed open; there is no limit state to report.
+		return nil
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(
+		"x-ratelimit-limit", strconv.Itoa(result.Limit),
+		"x-ratelimit-remaining", strconv.Itoa(result.Remaining),
+		"x-ratelimit-reset", strconv.FormatInt(result.ResetAt.Unix(), 10),
+	))
+	if errors.Is(err, middleware.ErrRateLimitExceeded) {
+		return status.Error(codes.ResourceExhausted, "too many requests")
+	}
+	return nil
+}
+
+// clientIP identifies anonymous callers, honouring x-forwarded-for from
+// proxies and falling back to the peer address.
+func clientIP(ctx context.Context, md metadata.MD) string {
+	if xff := first(md, "x-forwarded-for"); xff != "" {
+		ip, _, _ := strings.Cut(xff, ",")
+		return strings.TrimSpace(ip)
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return ""
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
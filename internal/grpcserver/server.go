@@ -0,0 +1,168 @@
+// Package grpcserver implements the geoid.v1.GeoService gRPC API over the
+// same service layer, API keys and rate limiters as the HTTP routes.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	geoidv1 "github.com/ikhsanfalakh/geo-id/api/geoid/v1"
+	"github.com/ikhsanfalakh/geo-id/internal/export"
+	"github.com/ikhsanfalakh/geo-id/internal/middleware"
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+// levels maps service levels to their protobuf enum values and back.
+var levels = map[service.Level]geoidv1.Level{
+	service.LevelState:    geoidv1.Level_LEVEL_STATE,
+	service.LevelCity:     geoidv1.Level_LEVEL_CITY,
+	service.LevelDistrict: geoidv1.Level_LEVEL_DISTRICT,
+	service.LevelVillage:  geoidv1.Level_LEVEL_VILLAGE,
+}
+
+// Server implements geoidv1.GeoServiceServer.
+type Server struct {
+	geoidv1.UnimplementedGeoServiceServer
+	Service *service.LocationService
+}
+
+// New returns a gRPC server with the GeoService registered behind the
+// request ID and tracing, API key and rate limit interceptors.
+func New(svc *service.LocationService, rateLimitCfg *middleware.RateLimitConfig) *grpc.Server {
+	limiter := &rateLimiter{cfg: rateLimitCfg}
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestUnary, limiter.unary),
+		grpc.ChainStreamInterceptor(requestStream, limiter.stream),
+	)
+	geoidv1.RegisterGeoServiceServer(srv, &Server{Service: svc})
+	return srv
+}
+
+func (s *Server) GetRegion(ctx context.Context, req *geoidv1.GetRegionRequest) (*geoidv1.GetRegionResponse, error) {
+	region, level, err := s.Service.GetRegion(ctx, req.GetCode())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &geoidv1.GetRegionResponse{Region: toProto(*region, level)}
+	if req.GetIncludeAncestors() {
+		ancestors, err := s.Service.Ancestors(ctx, req.GetCode())
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		resp.Ancestors = toProtoList(ancestors)
+	}
+	return resp, nil
+}
+
+func (s *Server) ListChildren(ctx context.Context, req *geoidv1.ListChildrenRequest) (*geoidv1.ListChildrenResponse, error) {
+	var children []model.Region
+	var err error
+	if req.GetCode() == "" {
+		children, err = s.Service.GetStates(ctx)
+	} else if _, _, err = s.Service.GetRegion(ctx, req.GetCode()); err == nil {
+		children, err = s.Service.GetChildren(ctx, req.GetCode())
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	result, err := service.ListQuery{
+		Limit:  int(req.GetLimit()),
+		Offset: int(req.GetOffset()),
+		Cursor: req.GetPageToken(),
+		Sort:   req.GetSort(),
+		Query:  req.GetQuery(),
+	}.Apply(children)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &geoidv1.ListChildrenResponse{
+		Regions:       toProtoList(result.Items),
+		Total:         int32(result.Pagination.Total),
+		NextPageToken: result.Pagination.NextCursor,
+	}, nil
+}
+
+func (s *Server) BatchGet(ctx context.Context, req *geoidv1.BatchGetRequest) (*geoidv1.BatchGetResponse, error) {
+	if n := len(req.GetCodes()); n == 0 || n > service.MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "codes must contain between 1 and %d entries", service.MaxBatchSize)
+	}
+	items, err := s.Service.BatchLookup(ctx, req.GetCodes(), req.GetIncludeAncestors())
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	resp := &geoidv1.BatchGetResponse{Items: make([]*geoidv1.BatchItem, len(items))}
+	for i, item := range items {
+		out := &geoidv1.BatchItem{Code: item.Code, Ancestors: toProtoList(item.Ancestors)}
+		if item.Region != nil {
+			out.Region = toProto(*item.Region, service.Level(item.Level))
+		}
+		if item.Error != nil {
+			out.Error = &geoidv1.BatchError{Code: item.Error.Code, Message: item.Error.Message}
+		}
+		resp.Items[i] = out
+	}
+	return resp, nil
+}
+
+func (s *Server) ExportRegions(req *geoidv1.ExportRegionsRequest, stream grpc.ServerStreamingServer[geoidv1.ExportRegionsResponse]) error {
+	opts := export.Options{Within: req.GetWithin()}
+	for level, value := range levels {
+		if value == req.GetLevel() {
+			opts.Level = level
+		}
+	}
+	if opts.Within != "" {
+		if _, _, err := s.Service.GetRegion(stream.Context(), opts.Within); err != nil {
+			return toStatus(err)
+		}
+	}
+
+	return export.Walk(stream.Context(), s.Service, opts, func(rec export.Record) error {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		parents := make([]*geoidv1.Region, len(rec.ParentCodes))
+		for i, code := range rec.ParentCodes {
+			level, _ := service.LevelOf(code)
+			parents[i] = toProto(model.Region{Code: code, Value: rec.ParentNames[i]}, level)
+		}
+		return stream.Send(&geoidv1.ExportRegionsResponse{
+			Region:  &geoidv1.Region{Code: rec.Code, Name: rec.Name, Level: levels[service.Level(rec.Level)], Type: rec.Type},
+			Parents: parents,
+		})
+	})
+}
+
+func toProto(r model.Region, level service.Level) *geoidv1.Region {
+	return &geoidv1.Region{
+		Code:  r.Code,
+		Name:  r.Value,
+		Level: levels[level],
+		Type:  service.RegionType(r),
+	}
+}
+
+func toProtoList(regions []model.Region) []*geoidv1.Region {
+	out := make([]*geoidv1.Region, len(regions))
+	for i, r := range regions {
+		level, _ := service.LevelOf(r.Code)
+		out[i] = toProto(r, level)
+	}
+	return out
+}
+
+// toStatus maps service errors to gRPC status codes.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCode), errors.Is(err, service.ErrInvalidQuery):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.NotFound, err.Error())
+	}
+}
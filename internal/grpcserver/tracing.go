@@ -0,0 +1,81 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
+)
+
+// metadataRequestID is the metadata key carrying the request ID, as the
+// X-Request-ID header does over HTTP.
+const metadataRequestID = "x-request-id"
+
+// tracer records the gRPC server spans.
+var tracer = otel.Tracer("github.com/ikhsanfalakh/geo-id/internal/grpcserver")
+
+// requestUnary and requestStream run before every other interceptor. They
+// propagate the caller's request ID, or generate one, and send it back as
+// header metadata, and continue the caller's trace (traceparent metadata)
+// in a server span named after the method.
+func requestUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, span := startCall(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	tracing.End(span, err)
+	return resp, err
+}
+
+func requestStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := startCall(ss.Context(), info.FullMethod)
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	tracing.End(span, err)
+	return err
+}
+
+func startCall(ctx context.Context, method string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := first(md, metadataRequestID)
+	if id == "" {
+		id = utils.UUIDv4()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, id))
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	return tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+			attribute.String("request.id", id),
+		),
+	)
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+// metadataCarrier reads propagation fields from incoming metadata.
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Get(key string) string { return first(metadata.MD(m), key) }
+
+func (m metadataCarrier) Set(key, value string) { metadata.MD(m).Set(key, value) }
+
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
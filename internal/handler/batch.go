@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+const (
+	// batchPath is the route of the batch lookup endpoint.
+	batchPath = "/regions/batch"
+	// localsBatchRequest is the Locals key holding the parsed batch
+	// request, so that the body is only parsed once.
+	localsBatchRequest = "batch_request"
+)
+
+// BatchGetRegions godoc
+// @Summary Batch lookup of regions
+// @Description Resolve up to 1000 codes of mixed levels in one call. Results preserve input order; codes that cannot be resolved carry a per-item error. Every 10 codes count as one request against the rate limit; batches costing more than the caller's whole limit are refused with 413.
+// @Tags regions
+// @Accept json
+// @Produce json
+// @Param request body model.BatchRequest true "Codes to resolve"
+// @Success 200 {object} model.APIResponse{data=[]model.BatchItem}
+// @Failure 400 {object} model.APIErrorResponse
+// @Failure 413 {object} model.APIErrorResponse
+// @Failure 500 {object} model.APIErrorResponse
+// @Router /regions/batch [post]
+func (h *LocationHandler) BatchGetRegions(c *fiber.Ctx) error {
+	req, err := parseBatchRequest(c)
+	if err != nil {
+		return badRequest(c, fmt.Errorf("%w: malformed JSON body", service.ErrInvalidQuery))
+	}
+	if len(req.Codes) == 0 || len(req.Codes) > service.MaxBatchSize {
+		return badRequest(c, fmt.Errorf("%w: codes must contain between 1 and %d entries", service.ErrInvalidQuery, service.MaxBatchSize))
+	}
+	items, err := h.Service.BatchLookup(c.UserContext(), req.Codes, req.IncludeAncestors)
+	if err != nil {
+		return render.Send(c, fiber.StatusInternalServerError, model.NewErrorResponse(
+			fiber.StatusInternalServerError,
+			"INTERNAL_SERVER_ERROR",
+			err,
+		))
+	}
+	return render.Send(c, fiber.StatusOK, model.NewSuccessResponse(items))
+}
+
+// RequestCost weighs batch lookups by the number of codes they carry and
+// GraphQL queries by their complexity, so they can be charged against the
+// rate limiter; every other request costs 1.
+func RequestCost(c *fiber.Ctx) int {
+	path := render.RoutePath(c)
+	if path == graphQLPath {
+		return graphQLCost(c)
+	}
+	if c.Method() != fiber.MethodPost || path != batchPath {
+		return 1
+	}
+	req, err := parseBatchRequest(c)
+	if err != nil {
+		return 1
+	}
+	return service.BatchCost(len(req.Codes))
+}
+
+// parseBatchRequest parses the body of a batch lookup, once: the result is
+// kept in Locals for the handler after RequestCost has weighed it.
+func parseBatchRequest(c *fiber.Ctx) (*model.BatchRequest, error) {
+	if req, ok := c.Locals(localsBatchRequest).(*model.BatchRequest); ok {
+		return req, nil
+	}
+	req := new(model.BatchRequest)
+	if err := json.Unmarshal(c.Body(), req); err != nil {
+		return nil, err
+	}
+	c.Locals(localsBatchRequest, req)
+	return req, nil
+}
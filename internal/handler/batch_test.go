@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+func TestBatchGetRegionsLoadError(t *testing.T) {
+	h := NewLocationHandler(service.NewLocationService(filepath.Join(t.TempDir(), "missing")))
+	app := fiber.New()
+	app.Post("/v2/regions/batch", h.BatchGetRegions)
+
+	req := httptest.NewRequest(fiber.MethodPost, "/v2/regions/batch", strings.NewReader(`{"codes":["32.73"]}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Fatalf("got status %d, want 500 rather than per-item NOT_FOUND", resp.StatusCode)
+	}
+}
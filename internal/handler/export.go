@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"bufio"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ikhsanfalakh/geo-id/internal/compress"
+	"github.com/ikhsanfalakh/geo-id/internal/export"
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+// GetExport godoc
+// @Summary Export regions
+// @Description Stream the flattened region hierarchy (code, name, level, type, parent codes and names). The response is compressed with Brotli, zstd or gzip when the client accepts it, and carries the dataset edition in the X-Data-Edition and X-Data-Updated-At headers.
+// @Tags regions
+// @Produce json
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Output format" Enums(csv, ndjson, json) default(csv)
+// @Param level query string false "Only export regions of this level" Enums(state, city, district, village)
+// @Param within query string false "Only export this region and its descendants (e.g. 11.01)"
+// @Success 200 {array} export.Record
+// @Failure 400 {object} model.APIErrorResponse
+// @Failure 404 {object} model.APIErrorResponse
+// @Router /export [get]
+func (h *LocationHandler) GetExport(c *fiber.Ctx) error {
+	opts := export.Options{
+		Format: exportFormat(c),
+		Level:  service.Level(c.Query("level")),
+		Within: c.Query("within"),
+	}
+	if err := opts.Validate(c.UserContext(), h.Service); err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) || errors.Is(err, service.ErrInvalidCode) {
+			return badRequest(c, err)
+		}
+		return render.Send(c, fiber.StatusNotFound, model.NewErrorResponse(
+			fiber.StatusNotFound,
+			"NOT_FOUND",
+			err,
+		))
+	}
+
+	edition, err := h.Service.Edition()
+	if err != nil {
+		return render.Send(c, fiber.StatusInternalServerError, model.NewErrorResponse(
+			fiber.StatusInternalServerError,
+			"INTERNAL_SERVER_ERROR",
+			err,
+		))
+	}
+
+	c.Set(fiber.HeaderContentType, export.ContentType(opts.Format))
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="geo-id-regions.`+opts.Format+`"`)
+	c.Set("X-Data-Edition", edition.Name)
+	c.Set("X-Data-Updated-At", edition.UpdatedAt.Format(time.RFC3339))
+	c.Vary(fiber.HeaderAcceptEncoding)
+
+	encoding := compress.Negotiate(c.Get(fiber.HeaderAcceptEncoding))
+	if encoding != compress.Identity {
+		c.Set(fiber.HeaderContentEncoding, encoding)
+	}
+
+	// The body is streamed after the handler returns; the export span
+	// still joins the request's trace.
+	svc, ctx := h.Service, c.UserContext()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		out, err := compress.NewWriter(w, encoding)
+		if err != nil {
+			slog.Warn("Export aborted", "format", opts.Format, "error", err)
+			return
+		}
+		defer out.Close()
+		if err := export.Write(ctx, out, svc, opts); err != nil {
+			slog.Warn("Export aborted", "format", opts.Format, "error", err)
+		}
+	})
+	return nil
+}
+
+// ExportFormat returns the format GetExport streams, selected by the format
+// parameter, so HTTPCache can derive the export's validators from it
+// rather than from the negotiated envelope format.
+func ExportFormat(c *fiber.Ctx) (string, bool) {
+	switch format := exportFormat(c); format {
+	case export.FormatCSV, export.FormatNDJSON, export.FormatJSON:
+		return format, true
+	}
+	return "", false
+}
+
+func exportFormat(c *fiber.Ctx) string {
+	return c.Query("format", export.FormatCSV)
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/middleware"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+var (
+	loadOnce sync.Once
+	testSvc  *service.LocationService
+	loadErr  error
+)
+
+// loadService returns the service on the repository's data directory,
+// loaded once for every test.
+func loadService(t *testing.T) *service.LocationService {
+	t.Helper()
+	loadOnce.Do(func() {
+		testSvc = service.NewLocationService("../../data")
+		loadErr = testSvc.Load()
+	})
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+	return testSvc
+}
+
+// exportApp serves /export under every prefix behind a rate limit of one
+// request per minute.
+func exportApp(t *testing.T) *fiber.App {
+	t.Helper()
+	h := NewLocationHandler(loadService(t))
+	keys, err := middleware.NewAPIKeyService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := &middleware.RateLimitConfig{
+		APIKeyService:    keys,
+		AnonymousLimiter: middleware.NewRateLimiter(1, time.Minute),
+		APIKeyLimiter:    middleware.NewRateLimiter(1, time.Minute),
+	}
+	t.Cleanup(limits.Stop)
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(middleware.RateLimitMiddleware(limits))
+	for _, prefix := range []string{"", "/v1", "/v2"} {
+		app.Get(prefix+"/export", h.GetExport)
+	}
+	return app
+}
+
+// TestExportErrorsIgnoreFormat checks that the export formats selected with
+// ?format= do not turn error responses into 406 Not Acceptable.
+func TestExportErrorsIgnoreFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		accept      string
+		wantStatus  int
+		wantCode    string
+		wantContent string
+	}{
+		{
+			name:        "unknown within",
+			url:         "/export?format=ndjson&within=99",
+			wantStatus:  fiber.StatusNotFound,
+			wantCode:    "NOT_FOUND",
+			wantContent: fiber.MIMEApplicationJSON,
+		},
+		{
+			name:        "unknown within v2",
+			url:         "/v2/export?format=ndjson&within=99",
+			wantStatus:  fiber.StatusNotFound,
+			wantCode:    "NOT_FOUND",
+			wantContent: "application/problem+json",
+		},
+		{
+			name:        "invalid level",
+			url:         "/v1/export?format=ndjson&level=planet",
+			wantStatus:  fiber.StatusBadRequest,
+			wantCode:    "BAD_REQUEST",
+			wantContent: fiber.MIMEApplicationJSON,
+		},
+		{
+			name:        "unknown format",
+			url:         "/v1/export?format=yaml",
+			wantStatus:  fiber.StatusBadRequest,
+			wantCode:    "BAD_REQUEST",
+			wantContent: fiber.MIMEApplicationJSON,
+		},
+		{
+			name:        "errors follow Accept",
+			url:         "/export/?format=ndjson&within=99",
+			accept:      "application/xml",
+			wantStatus:  fiber.StatusNotFound,
+			wantContent: fiber.MIMEApplicationXMLCharsetUTF8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set(fiber.HeaderAccept, tt.accept)
+			}
+			resp, err := exportApp(t).Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get(fiber.HeaderContentType); got != tt.wantContent {
+				t.Fatalf("got Content-Type %q, want %q", got, tt.wantContent)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var body struct {
+				Message string `json:"message"`
+				Code    string `json:"code"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Message != tt.wantCode && body.Code != tt.wantCode {
+				t.Fatalf("got %+v, want %s", body, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestExportRateLimitIgnoresFormat(t *testing.T) {
+	app := exportApp(t)
+	for i, want := range []int{fiber.StatusOK, fiber.StatusTooManyRequests} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/v1/export?format=ndjson&within=32.73.01", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("request %d: got status %d, want %d", i+1, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestExportCacheValidators(t *testing.T) {
+	h := NewLocationHandler(loadService(t))
+	app := fiber.New()
+	app.Get("/export", middleware.HTTPCache(h.Service, time.Hour, ExportFormat), h.GetExport)
+
+	etags := map[string]string{}
+	for _, format := range []string{"csv", "ndjson", "json"} {
+		// The export format, not Accept, selects the representation.
+		req := httptest.NewRequest(fiber.MethodGet, "/export?within=32.73.01&format="+format, nil)
+		req.Header.Set(fiber.HeaderAccept, "application/x-ndjson")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		etag := resp.Header.Get(fiber.HeaderETag)
+		if resp.StatusCode != fiber.StatusOK || etag == "" || resp.Header.Get(fiber.HeaderLastModified) == "" {
+			t.Fatalf("format %s: got status %d, ETag %q; want 200 with validators", format, resp.StatusCode, etag)
+		}
+		if got := resp.Header.Get(fiber.HeaderCacheControl); got != "public, max-age=3600" {
+			t.Fatalf("format %s: got Cache-Control %q", format, got)
+		}
+		for other, otherETag := range etags {
+			if etag == otherETag {
+				t.Fatalf("formats %s and %s share ETag %s", format, other, etag)
+			}
+		}
+		etags[format] = etag
+	}
+
+	req := httptest.NewRequest(fiber.MethodGet, "/export?within=32.73.01&format=ndjson", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, etags["ndjson"])
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusNotModified {
+		t.Fatalf("revalidation: got status %d, want 304", resp.StatusCode)
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+)
+
+// NotFound answers requests that matched no route. v2 routes get a problem
+// details body; unversioned and v1 routes keep Fiber's default response.
+func NotFound(c *fiber.Ctx) error {
+	if render.VersionOf(c) != render.V2 {
+		return c.Next()
+	}
+	return render.Send(c, fiber.StatusNotFound, model.NewErrorResponse(
+		fiber.StatusNotFound,
+		"NOT_FOUND",
+		fmt.Errorf("no route for %s %s", c.Method(), c.Path()),
+	))
+}
+
+// ErrorHandler writes errors returned by handlers and middleware. On v2
+// routes they become problem details; elsewhere Fiber's default handler is
+// used.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	if render.VersionOf(c) != render.V2 {
+		return fiber.DefaultErrorHandler(c, err)
+	}
+	status := fiber.StatusInternalServerError
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		status = fe.Code
+	}
+	return render.Send(c, status, model.NewErrorResponse(status, errorCode(status), err))
+}
+
+// errorCode derives an error code such as NOT_FOUND from an HTTP status.
+func errorCode(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return "BAD_REQUEST"
+	case fiber.StatusNotFound:
+		return "NOT_FOUND"
+	case fiber.StatusMethodNotAllowed:
+		return "METHOD_NOT_ALLOWED"
+	case fiber.StatusRequestEntityTooLarge:
+		return "PAYLOAD_TOO_LARGE"
+	}
+	if status >= fiber.StatusInternalServerError {
+		return "INTERNAL_SERVER_ERROR"
+	}
+	return "ERROR"
+}
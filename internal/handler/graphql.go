@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+
+	"github.com/ikhsanfalakh/geo-id/internal/gql"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+const (
+	// graphQLPath is the route of the GraphQL endpoint.
+	graphQLPath = "/graphql"
+
+	// graphQLComplexityPerUnit is how much estimated query complexity counts
+	// as one request against the rate limit.
+	graphQLComplexityPerUnit = 100
+)
+
+// GraphQLHandler serves GraphQL queries over the same service as LocationHandler.
+type GraphQLHandler struct {
+	Schema graphql.Schema
+}
+
+func NewGraphQLHandler(s *service.LocationService) (*GraphQLHandler, error) {
+	schema, err := gql.NewSchema(s)
+	if err != nil {
+		return nil, err
+	}
+	return &GraphQLHandler{Schema: schema}, nil
+}
+
+// graphQLRequest is a GraphQL-over-HTTP request.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// parseGraphQLRequest reads the query from a JSON POST body, or from the
+// query string for GET requests.
+func parseGraphQLRequest(c *fiber.Ctx) (graphQLRequest, error) {
+	var req graphQLRequest
+	if c.Method() == fiber.MethodPost {
+		if err := json.Unmarshal(c.Body(), &req); err != nil {
+			return req, errors.New("malformed JSON body")
+		}
+	} else {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if vars := c.Query("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				return req, errors.New("variables must be a JSON object")
+			}
+		}
+	}
+	if req.Query == "" {
+		return req, errors.New("query is required")
+	}
+	return req, nil
+}
+
+// Serve godoc
+// @Summary GraphQL endpoint
+// @Description Query the region hierarchy with GraphQL (state, city, district, village, region, search, and parent/children on Region). Queries are limited in depth and estimated complexity; every 100 points of complexity count as one request against the rate limit.
+// @Tags regions
+// @Accept json
+// @Produce json
+// @Param query query string false "GraphQL query (GET requests)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /graphql [post]
+func (h *GraphQLHandler) Serve(c *fiber.Ctx) error {
+	req, err := parseGraphQLRequest(c)
+	if err != nil {
+		return graphQLError(c, err)
+	}
+	if _, err := gql.Analyze(req.Query, req.Variables); err != nil {
+		return graphQLError(c, err)
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.Schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        c.UserContext(),
+	})
+	status := fiber.StatusOK
+	if result.Data == nil && result.HasErrors() {
+		status = fiber.StatusBadRequest
+	}
+	if result.HasErrors() {
+		result.Extensions = requestIDExtension(c)
+	}
+	return c.Status(status).JSON(result)
+}
+
+// graphQLError writes a request-level error in the GraphQL response format.
+func graphQLError(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(graphql.Result{
+		Errors:     []gqlerrors.FormattedError{gqlerrors.FormatError(err)},
+		Extensions: requestIDExtension(c),
+	})
+}
+
+// requestIDExtension returns the response extensions identifying the
+// request in error responses.
+func requestIDExtension(c *fiber.Ctx) map[string]interface{} {
+	return map[string]interface{}{"request_id": render.RequestID(c)}
+}
+
+// graphQLCost weighs a GraphQL request by its estimated complexity. Queries
+// that will be rejected by the handler cost 1.
+func graphQLCost(c *fiber.Ctx) int {
+	req, err := parseGraphQLRequest(c)
+	if err != nil {
+		return 1
+	}
+	cost, err := gql.Analyze(req.Query, req.Variables)
+	if err != nil || cost.Complexity <= graphQLComplexityPerUnit {
+		return 1
+	}
+	return (cost.Complexity + graphQLComplexityPerUnit - 1) / graphQLComplexityPerUnit
+}
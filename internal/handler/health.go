@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ikhsanfalakh/geo-id/internal/health"
+)
+
+// HealthHandler serves the actuator health endpoints. Their bodies are the
+// same on every API version, since probes parse them independently of the
+// API envelope.
+type HealthHandler struct {
+	Tracker *health.Tracker
+}
+
+func NewHealthHandler(t *health.Tracker) *HealthHandler {
+	return &HealthHandler{Tracker: t}
+}
+
+// Liveness godoc
+// @Summary Liveness probe
+// @Description Report that the process is up. It does not depend on the dataset.
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.HealthStatus
+// @Router /actuator/health/liveness [get]
+func (h *HealthHandler) Liveness(c *fiber.Ctx) error {
+	return c.JSON(h.Tracker.Liveness())
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Report whether the dataset is loaded and validated, with its edition, region counts, load time and uptime. Returns 503 until the server is ready to serve traffic.
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.HealthStatus
+// @Failure 503 {object} model.HealthStatus
+// @Router /actuator/health/readiness [get]
+func (h *HealthHandler) Readiness(c *fiber.Ctx) error {
+	report := h.Tracker.Readiness()
+	status := fiber.StatusOK
+	if report.Status != health.StatusUp {
+		status = fiber.StatusServiceUnavailable
+	}
+	return c.Status(status).JSON(report)
+}
@@ -1,8 +1,17 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/ikhsanfalakh/geo-id/internal/middleware"
 	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
 	"github.com/ikhsanfalakh/geo-id/internal/service"
 )
 
@@ -19,19 +28,25 @@ func NewLocationHandler(s *service.LocationService) *LocationHandler {
 // @Description Get list of all provinces in Indonesia
 // @Tags states
 // @Produce json
+// @Param limit query int false "Page size (1-1000); omit to return the whole list"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Opaque cursor from a previous response's pagination.next_cursor"
+// @Param sort query string false "Sort order" Enums(code, name)
+// @Param q query string false "Only return regions whose name contains this text"
 // @Success 200 {object} model.APIResponse{data=[]model.Region}
+// @Failure 400 {object} model.APIErrorResponse
 // @Failure 500 {object} model.APIErrorResponse
 // @Router /states [get]
 func (h *LocationHandler) GetStates(c *fiber.Ctx) error {
-	states, err := h.Service.GetStates()
+	states, err := h.Service.GetStates(c.UserContext())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.NewErrorResponse(
+		return render.Send(c, fiber.StatusInternalServerError, model.NewErrorResponse(
 			fiber.StatusInternalServerError,
 			"INTERNAL_SERVER_ERROR",
 			err,
 		))
 	}
-	return c.JSON(model.NewSuccessResponse(states))
+	return h.sendList(c, states)
 }
 
 // GetState godoc
@@ -40,20 +55,13 @@ func (h *LocationHandler) GetStates(c *fiber.Ctx) error {
 // @Tags states
 // @Produce json
 // @Param id path string true "State Code (e.g. 11)"
+// @Param expand query string false "Nest child levels, e.g. cities.districts (capped at 10000 regions)"
 // @Success 200 {object} model.APIResponse{data=model.Region}
+// @Failure 400 {object} model.APIErrorResponse
 // @Failure 404 {object} model.APIErrorResponse
 // @Router /states/{id} [get]
 func (h *LocationHandler) GetState(c *fiber.Ctx) error {
-	id := c.Params("id")
-	state, err := h.Service.GetState(id)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.NewErrorResponse(
-			fiber.StatusNotFound,
-			"NOT_FOUND",
-			err,
-		))
-	}
-	return c.JSON(model.NewSuccessResponse(state))
+	return h.sendRegionAt(c, c.Params("id"), service.LevelState)
 }
 
 // GetCities godoc
@@ -62,20 +70,17 @@ func (h *LocationHandler) GetState(c *fiber.Ctx) error {
 // @Tags states
 // @Produce json
 // @Param id path string true "State Code (e.g. 11)"
+// @Param limit query int false "Page size (1-1000); omit to return the whole list"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Opaque cursor from a previous response's pagination.next_cursor"
+// @Param sort query string false "Sort order" Enums(code, name)
+// @Param q query string false "Only return regions whose name contains this text"
 // @Success 200 {object} model.APIResponse{data=[]model.Region}
+// @Failure 400 {object} model.APIErrorResponse
 // @Failure 404 {object} model.APIErrorResponse
 // @Router /states/{id}/cities [get]
 func (h *LocationHandler) GetCities(c *fiber.Ctx) error {
-	id := c.Params("id")
-	cities, err := h.Service.GetCities(id)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.NewErrorResponse(
-			fiber.StatusNotFound,
-			"NOT_FOUND",
-			err,
-		))
-	}
-	return c.JSON(model.NewSuccessResponse(cities))
+	return h.sendChildren(c, c.Params("id"), service.LevelState)
 }
 
 // GetCity godoc
@@ -84,20 +89,13 @@ func (h *LocationHandler) GetCities(c *fiber.Ctx) error {
 // @Tags cities
 // @Produce json
 // @Param id path string true "City Code (e.g. 11.01)"
+// @Param expand query string false "Nest child levels, e.g. districts.villages (capped at 10000 regions)"
 // @Success 200 {object} model.APIResponse{data=model.Region}
+// @Failure 400 {object} model.APIErrorResponse
 // @Failure 404 {object} model.APIErrorResponse
 // @Router /cities/{id} [get]
 func (h *LocationHandler) GetCity(c *fiber.Ctx) error {
-	id := c.Params("id")
-	city, err := h.Service.GetCity(id)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.NewErrorResponse(
-			fiber.StatusNotFound,
-			"NOT_FOUND",
-			err,
-		))
-	}
-	return c.JSON(model.NewSuccessResponse(city))
+	return h.sendRegionAt(c, c.Params("id"), service.LevelCity)
 }
 
 // GetDistricts godoc
@@ -106,20 +104,17 @@ func (h *LocationHandler) GetCity(c *fiber.Ctx) error {
 // @Tags cities
 // @Produce json
 // @Param id path string true "City Code (e.g. 11.01)"
+// @Param limit query int false "Page size (1-1000); omit to return the whole list"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Opaque cursor from a previous response's pagination.next_cursor"
+// @Param sort query string false "Sort order" Enums(code, name)
+// @Param q query string false "Only return regions whose name contains this text"
 // @Success 200 {object} model.APIResponse{data=[]model.Region}
+// @Failure 400 {object} model.APIErrorResponse
 // @Failure 404 {object} model.APIErrorResponse
 // @Router /cities/{id}/districts [get]
 func (h *LocationHandler) GetDistricts(c *fiber.Ctx) error {
-	id := c.Params("id")
-	districts, err := h.Service.GetDistricts(id)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.NewErrorResponse(
-			fiber.StatusNotFound,
-			"NOT_FOUND",
-			err,
-		))
-	}
-	return c.JSON(model.NewSuccessResponse(districts))
+	return h.sendChildren(c, c.Params("id"), service.LevelCity)
 }
 
 // GetDistrict godoc
@@ -128,20 +123,13 @@ func (h *LocationHandler) GetDistricts(c *fiber.Ctx) error {
 // @Tags districts
 // @Produce json
 // @Param id path string true "District Code (e.g. 11.01.01)"
+// @Param expand query string false "Nest child levels, e.g. villages (capped at 10000 regions)"
 // @Success 200 {object} model.APIResponse{data=model.Region}
+// @Failure 400 {object} model.APIErrorResponse
 // @Failure 404 {object} model.APIErrorResponse
 // @Router /districts/{id} [get]
 func (h *LocationHandler) GetDistrict(c *fiber.Ctx) error {
-	id := c.Params("id")
-	district, err := h.Service.GetDistrict(id)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.NewErrorResponse(
-			fiber.StatusNotFound,
-			"NOT_FOUND",
-			err,
-		))
-	}
-	return c.JSON(model.NewSuccessResponse(district))
+	return h.sendRegionAt(c, c.Params("id"), service.LevelDistrict)
 }
 
 // GetVillages godoc
@@ -150,20 +138,17 @@ func (h *LocationHandler) GetDistrict(c *fiber.Ctx) error {
 // @Tags districts
 // @Produce json
 // @Param id path string true "District Code (e.g. 11.01.01)"
+// @Param limit query int false "Page size (1-1000); omit to return the whole list"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Opaque cursor from a previous response's pagination.next_cursor"
+// @Param sort query string false "Sort order" Enums(code, name)
+// @Param q query string false "Only return regions whose name contains this text"
 // @Success 200 {object} model.APIResponse{data=[]model.Region}
+// @Failure 400 {object} model.APIErrorResponse
 // @Failure 404 {object} model.APIErrorResponse
 // @Router /districts/{id}/villages [get]
 func (h *LocationHandler) GetVillages(c *fiber.Ctx) error {
-	id := c.Params("id")
-	villages, err := h.Service.GetVillages(id)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.NewErrorResponse(
-			fiber.StatusNotFound,
-			"NOT_FOUND",
-			err,
-		))
-	}
-	return c.JSON(model.NewSuccessResponse(villages))
+	return h.sendChildren(c, c.Params("id"), service.LevelDistrict)
 }
 
 // GetVillage godoc
@@ -173,17 +158,237 @@ func (h *LocationHandler) GetVillages(c *fiber.Ctx) error {
 // @Produce json
 // @Param id path string true "Village Code (e.g. 11.01.01.2001)"
 // @Success 200 {object} model.APIResponse{data=model.Region}
+// @Failure 400 {object} model.APIErrorResponse
 // @Failure 404 {object} model.APIErrorResponse
 // @Router /villages/{id} [get]
 func (h *LocationHandler) GetVillage(c *fiber.Ctx) error {
-	id := c.Params("id")
-	village, err := h.Service.GetVillage(id)
+	return h.sendRegionAt(c, c.Params("id"), service.LevelVillage)
+}
+
+// parseListQuery reads the pagination, sorting and filtering query parameters
+// shared by every list endpoint.
+func parseListQuery(c *fiber.Ctx) (service.ListQuery, error) {
+	q := service.ListQuery{
+		Cursor: c.Query("cursor"),
+		Sort:   c.Query("sort"),
+		Query:  c.Query("q"),
+	}
+	for name, dst := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return q, fmt.Errorf("%w: %s must be an integer", service.ErrInvalidQuery, name)
+		}
+		*dst = n
+	}
+	// The whole list is returned without a limit; an explicit one must
+	// be a page size.
+	if c.Query("limit") != "" && (q.Limit < 1 || q.Limit > service.MaxListLimit) {
+		return q, fmt.Errorf("%w: limit must be between 1 and %d", service.ErrInvalidQuery, service.MaxListLimit)
+	}
+	return q, nil
+}
+
+// sendList applies the request's list query to regions and writes the page
+// together with its pagination metadata.
+func (h *LocationHandler) sendList(c *fiber.Ctx, regions []model.Region) error {
+	q, err := parseListQuery(c)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.NewErrorResponse(
-			fiber.StatusNotFound,
-			"NOT_FOUND",
+		return badRequest(c, err)
+	}
+	result, err := q.Apply(regions)
+	if err != nil {
+		return badRequest(c, err)
+	}
+	return render.Send(c, fiber.StatusOK, model.NewPaginatedResponse(result.Items, result.Pagination))
+}
+
+// badRequest writes a 400 response for invalid client input.
+func badRequest(c *fiber.Ctx, err error) error {
+	return render.Send(c, fiber.StatusBadRequest, model.NewErrorResponse(
+		fiber.StatusBadRequest,
+		"BAD_REQUEST",
+		err,
+	))
+}
+
+// resolve looks up the region with the given code. An empty level accepts
+// a code of any level and reports malformed codes as ErrInvalidCode;
+// otherwise the code must belong to that level.
+func (h *LocationHandler) resolve(ctx context.Context, code string, want service.Level) (*model.Region, service.Level, error) {
+	region, level, err := h.Service.GetRegion(ctx, code)
+	if want == "" {
+		return region, level, err
+	}
+	if err != nil || level != want {
+		return nil, "", fmt.Errorf("%s not found", want)
+	}
+	return region, level, nil
+}
+
+// regionError writes the response for a failed resolve.
+func regionError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrInvalidCode) {
+		return badRequest(c, err)
+	}
+	return render.Send(c, fiber.StatusNotFound, model.NewErrorResponse(
+		fiber.StatusNotFound,
+		"NOT_FOUND",
+		err,
+	))
+}
+
+// sendRegionAt writes the region with the given code, which must be of the
+// given level.
+func (h *LocationHandler) sendRegionAt(c *fiber.Ctx, code string, level service.Level) error {
+	region, _, err := h.resolve(c.UserContext(), code, level)
+	if err != nil {
+		return regionError(c, err)
+	}
+	return h.sendRegion(c, region, level)
+}
+
+// sendChildren writes the children of the region with the given code as a
+// list. An empty level accepts a parent code of any level.
+func (h *LocationHandler) sendChildren(c *fiber.Ctx, code string, level service.Level) error {
+	if _, _, err := h.resolve(c.UserContext(), code, level); err != nil {
+		return regionError(c, err)
+	}
+	children, err := h.Service.GetChildren(c.UserContext(), code)
+	if err != nil {
+		return render.Send(c, fiber.StatusInternalServerError, model.NewErrorResponse(
+			fiber.StatusInternalServerError,
+			"INTERNAL_SERVER_ERROR",
 			err,
 		))
 	}
-	return c.JSON(model.NewSuccessResponse(village))
+	return h.sendList(c, children)
+}
+
+// sendRegion writes a single region, nesting its descendants when the
+// request carries an expand parameter.
+func (h *LocationHandler) sendRegion(c *fiber.Ctx, region *model.Region, level service.Level) error {
+	depth, err := service.ExpandDepth(c.Query("expand"), level)
+	if err != nil {
+		return badRequest(c, err)
+	}
+	if depth == 0 {
+		return render.Send(c, fiber.StatusOK, model.NewSuccessResponse(region))
+	}
+	tree, err := h.Service.GetTree(c.UserContext(), region.Code, depth)
+	if err != nil {
+		return h.treeError(c, err)
+	}
+	return render.Send(c, fiber.StatusOK, model.NewSuccessResponse(tree))
+}
+
+// treeError maps errors from building a region tree to a response.
+func (h *LocationHandler) treeError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrTreeTooLarge) || errors.Is(err, service.ErrInvalidCode) {
+		return badRequest(c, err)
+	}
+	return render.Send(c, fiber.StatusInternalServerError, model.NewErrorResponse(
+		fiber.StatusInternalServerError,
+		"INTERNAL_SERVER_ERROR",
+		err,
+	))
+}
+
+// Precompress adds the JSON body of every region list endpoint, as sendList
+// renders it for a request without query parameters, to store. Each
+// children list is stored under both its level-specific and generic path,
+// once per API version prefix. The bodies
+// are compressed in parallel, one list per CPU at a time.
+func (h *LocationHandler) Precompress(ctx context.Context, store *middleware.Precompressed) error {
+	type list struct {
+		paths   []string
+		regions []model.Region
+	}
+	var lists []list
+
+	states, err := h.Service.GetStates(ctx)
+	if err != nil {
+		return err
+	}
+	lists = append(lists, list{[]string{"/states"}, states})
+	for _, state := range states {
+		cities, err := h.Service.GetCities(ctx, state.Code)
+		if err != nil {
+			return err
+		}
+		lists = append(lists, list{childPaths("/states/", state.Code, "/cities"), cities})
+		for _, city := range cities {
+			districts, err := h.Service.GetDistricts(ctx, city.Code)
+			if err != nil {
+				return err
+			}
+			lists = append(lists, list{childPaths("/cities/", city.Code, "/districts"), districts})
+			for _, district := range districts {
+				villages, err := h.Service.GetVillages(ctx, district.Code)
+				if err != nil {
+					return err
+				}
+				lists = append(lists, list{childPaths("/districts/", district.Code, "/villages"), villages})
+			}
+		}
+	}
+
+	jobs := make(chan list)
+	errs := make(chan error, runtime.NumCPU())
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			var firstErr error
+			for l := range jobs {
+				if firstErr == nil {
+					firstErr = precompressList(store, l.regions, l.paths)
+				}
+			}
+			errs <- firstErr
+		}()
+	}
+	for _, l := range lists {
+		jobs <- l
+	}
+	close(jobs)
+
+	var firstErr error
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// childPaths returns the level-specific and generic paths of a children list.
+func childPaths(prefix, code, suffix string) []string {
+	return []string{prefix + code + suffix, "/regions/" + code + "/children"}
+}
+
+func precompressList(store *middleware.Precompressed, regions []model.Region, paths []string) error {
+	result, err := service.ListQuery{}.Apply(regions)
+	if err != nil {
+		return err
+	}
+	resp := model.NewPaginatedResponse(result.Items, result.Pagination)
+	for _, v := range render.Versions {
+		envelope, _ := render.Envelope(v, resp, "")
+		body, err := json.Marshal(envelope)
+		if err != nil {
+			return err
+		}
+		var mounted []string
+		for _, prefix := range v.Prefixes() {
+			for _, path := range paths {
+				mounted = append(mounted, prefix+path)
+			}
+		}
+		if err := store.Add(body, mounted...); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
+)
+
+// DecodeNIK godoc
+// @Summary Decode a NIK
+// @Description Check the structure of a NIK (national identity number) and decode it: the region prefix is resolved to the province, city and district of registration and flagged as current, past (only found in a past dataset edition) or unknown, and the birth date, gender and serial are returned. Responses are never cached.
+// @Tags nik
+// @Produce json
+// @Param nik path string true "16 digit NIK (e.g. 3273014101900001)"
+// @Success 200 {object} model.APIResponse{data=model.NIKDecoding}
+// @Failure 400 {object} model.APIErrorResponse
+// @Router /nik/{nik}/decode [get]
+func (h *LocationHandler) DecodeNIK(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	decoded, err := h.Service.DecodeNIK(c.UserContext(), c.Params("nik"))
+	if errors.Is(err, geoid.ErrInvalidNIK) {
+		return badRequest(c, err)
+	}
+	if err != nil {
+		return render.Send(c, fiber.StatusInternalServerError, model.NewErrorResponse(
+			fiber.StatusInternalServerError,
+			"INTERNAL_SERVER_ERROR",
+			err,
+		))
+	}
+	return render.Send(c, fiber.StatusOK, model.NewSuccessResponse(model.NIKDecoding{
+		NIK:          decoded.Number,
+		State:        toModelRegion(decoded.State),
+		City:         toModelRegion(decoded.City),
+		District:     toModelRegion(decoded.District),
+		RegionStatus: string(decoded.Status),
+		Edition:      decoded.Edition,
+		BirthDate:    decoded.BirthDate.Format(time.DateOnly),
+		Gender:       string(decoded.Gender),
+		Serial:       decoded.Serial,
+	}))
+}
+
+// toModelRegion converts a library region to the API model, keeping nil.
+func toModelRegion(r *geoid.Region) *model.Region {
+	if r == nil {
+		return nil
+	}
+	return &model.Region{Code: r.Code, Value: r.Name}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+// maxTreeDepth is the deepest tree that can be requested (state down to villages).
+const maxTreeDepth = 3
+
+// GetRegionByCode godoc
+// @Summary Get region by code
+// @Description Get a region of any level; the level is inferred from the code format. The response links to the region's parent and children.
+// @Tags regions
+// @Produce json
+// @Param code path string true "Region Code of any level (e.g. 11, 11.01, 11.01.01 or 11.01.01.2001)"
+// @Success 200 {object} model.APIResponse{data=model.RegionDetail}
+// @Failure 400 {object} model.APIErrorResponse
+// @Failure 404 {object} model.APIErrorResponse
+// @Router /regions/{code} [get]
+func (h *LocationHandler) GetRegionByCode(c *fiber.Ctx) error {
+	region, level, err := h.resolve(c.UserContext(), c.Params("code"), "")
+	if err != nil {
+		return regionError(c, err)
+	}
+	return render.Send(c, fiber.StatusOK, model.NewSuccessResponse(newRegionDetail(*region, level, render.PathPrefix(c))))
+}
+
+// GetRegionChildren godoc
+// @Summary Get children of a region
+// @Description Get the direct children of a region of any level (cities of a state, districts of a city, villages of a district). Villages have no children.
+// @Tags regions
+// @Produce json
+// @Param code path string true "Region Code of any level (e.g. 11 or 11.01)"
+// @Param limit query int false "Page size (1-1000); omit to return the whole list"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "Opaque cursor from a previous response's pagination.next_cursor"
+// @Param sort query string false "Sort order" Enums(code, name)
+// @Param q query string false "Only return regions whose name contains this text"
+// @Success 200 {object} model.APIResponse{data=[]model.Region}
+// @Failure 400 {object} model.APIErrorResponse
+// @Failure 404 {object} model.APIErrorResponse
+// @Router /regions/{code}/children [get]
+func (h *LocationHandler) GetRegionChildren(c *fiber.Ctx) error {
+	return h.sendChildren(c, c.Params("code"), "")
+}
+
+// GetRegionTree godoc
+// @Summary Get region tree
+// @Description Get a region of any level with its descendants nested as children, up to depth levels deep
+// @Tags regions
+// @Produce json
+// @Param code path string true "Region Code of any level (e.g. 11 or 11.01)"
+// @Param depth query int false "Number of child levels to include (0-3, default 1)"
+// @Success 200 {object} model.APIResponse{data=model.Region}
+// @Failure 400 {object} model.APIErrorResponse
+// @Failure 404 {object} model.APIErrorResponse
+// @Router /regions/{code}/tree [get]
+func (h *LocationHandler) GetRegionTree(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if _, err := service.LevelOf(code); err != nil {
+		return badRequest(c, err)
+	}
+
+	depth := 1
+	if raw := c.Query("depth"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > maxTreeDepth {
+			return badRequest(c, fmt.Errorf("%w: depth must be between 0 and %d", service.ErrInvalidQuery, maxTreeDepth))
+		}
+		depth = n
+	}
+
+	if _, _, err := h.resolve(c.UserContext(), code, ""); err != nil {
+		return regionError(c, err)
+	}
+
+	tree, err := h.Service.GetTree(c.UserContext(), code, depth)
+	if err != nil {
+		return h.treeError(c, err)
+	}
+	return render.Send(c, fiber.StatusOK, model.NewSuccessResponse(tree))
+}
+
+// newRegionDetail describes region with its level and the paths of its
+// parent and children, under the given API version prefix.
+func newRegionDetail(region model.Region, level service.Level, prefix string) model.RegionDetail {
+	base := prefix + "/regions/"
+	detail := model.RegionDetail{
+		Code:       region.Code,
+		Value:      region.Value,
+		Level:      string(level),
+		Type:       service.RegionType(region),
+		ParentCode: service.ParentCode(region.Code),
+		Links:      model.RegionLinks{Self: base + region.Code},
+	}
+	if detail.ParentCode != "" {
+		detail.Links.Parent = base + detail.ParentCode
+	}
+	if service.ChildLevel(level) != "" {
+		detail.Links.Children = base + region.Code + "/children"
+	}
+	return detail
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
+)
+
+// Routes holds the handlers and middleware mounted under each API version
+// prefix. Every field must be set.
+type Routes struct {
+	AppName    string
+	AppVersion string
+
+	Locations *LocationHandler
+	GraphQL   *GraphQLHandler
+
+	// RegionCache and ExportCache set the HTTP caching headers of the
+	// region and export routes.
+	RegionCache fiber.Handler
+	ExportCache fiber.Handler
+	// ListCompress compresses the region lists, serving the precompressed
+	// ones; DynamicCompress compresses the other responses.
+	ListCompress    fiber.Handler
+	DynamicCompress fiber.Handler
+}
+
+// Register mounts the API on r.
+func (rt Routes) Register(r fiber.Router) {
+	h := rt.Locations
+
+	r.Get("/", func(c *fiber.Ctx) error {
+		return render.Send(c, fiber.StatusOK, model.NewSuccessResponse(fiber.Map{
+			"app":      rt.AppName,
+			"version":  rt.AppVersion,
+			"docs_url": "/apidocs/index.html",
+			"message":  "Welcome to Geo-ID API",
+		}))
+	})
+
+	states := r.Group("/states", rt.RegionCache, rt.ListCompress)
+	states.Get("", handle("GetStates", h.GetStates))
+	states.Get("/:id", handle("GetState", h.GetState))
+	states.Get("/:id/cities", handle("GetCities", h.GetCities))
+
+	cities := r.Group("/cities", rt.RegionCache, rt.ListCompress)
+	cities.Get("/:id", handle("GetCity", h.GetCity))
+	cities.Get("/:id/districts", handle("GetDistricts", h.GetDistricts))
+
+	districts := r.Group("/districts", rt.RegionCache, rt.ListCompress)
+	districts.Get("/:id", handle("GetDistrict", h.GetDistrict))
+	districts.Get("/:id/villages", handle("GetVillages", h.GetVillages))
+
+	villages := r.Group("/villages", rt.RegionCache, rt.DynamicCompress)
+	villages.Get("/:id", handle("GetVillage", h.GetVillage))
+
+	regions := r.Group("/regions", rt.RegionCache, rt.ListCompress)
+	regions.Post("/batch", handle("BatchGetRegions", h.BatchGetRegions))
+	regions.Get("/:code", handle("GetRegionByCode", h.GetRegionByCode))
+	regions.Get("/:code/children", handle("GetRegionChildren", h.GetRegionChildren))
+	regions.Get("/:code/tree", handle("GetRegionTree", h.GetRegionTree))
+
+	r.Get("/export", rt.ExportCache, handle("GetExport", h.GetExport))
+
+	// NIKs are personal data: the responses are not cached (see DecodeNIK)
+	// and the NIK is masked in access logs and traces.
+	r.Get("/nik/:nik/decode", rt.DynamicCompress, handle("DecodeNIK", h.DecodeNIK))
+
+	r.Get("/graphql", rt.DynamicCompress, handle("GraphQL", rt.GraphQL.Serve))
+	r.Post("/graphql", rt.DynamicCompress, handle("GraphQL", rt.GraphQL.Serve))
+}
+
+// handle wraps a route handler in a span named after it.
+func handle(name string, h fiber.Handler) fiber.Handler {
+	return tracing.Wrap("handler."+name, h)
+}
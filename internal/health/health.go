@@ -0,0 +1,103 @@
+// Package health tracks whether the server is alive and ready to serve
+// traffic, for the actuator health endpoints.
+package health
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+)
+
+// Health status values, following Spring Boot actuator conventions.
+const (
+	StatusUp           = "UP"
+	StatusOutOfService = "OUT_OF_SERVICE"
+)
+
+// Tracker records the readiness of the server. It starts not ready; the
+// startup code marks it ready once the dataset is loaded and validated, and
+// may mark it not ready again, e.g. while shutting down.
+type Tracker struct {
+	startedAt time.Time
+
+	mu           sync.RWMutex
+	ready        bool
+	reason       string
+	edition      model.Edition
+	counts       model.RegionCounts
+	loadedAt     time.Time
+	loadDuration time.Duration
+}
+
+// NewTracker returns a tracker for a server starting now.
+func NewTracker() *Tracker {
+	return &Tracker{startedAt: time.Now(), reason: "loading dataset"}
+}
+
+// SetReady records a successful data load that took loadDuration and
+// marks the server ready.
+func (t *Tracker) SetReady(edition model.Edition, counts model.RegionCounts, loadDuration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ready = true
+	t.reason = ""
+	t.edition = edition
+	t.counts = counts
+	t.loadedAt = time.Now()
+	t.loadDuration = loadDuration
+}
+
+// SetNotReady marks the server not ready, reporting reason.
+func (t *Tracker) SetNotReady(reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ready = false
+	t.reason = reason
+}
+
+// Ready reports whether the server is ready to serve traffic.
+func (t *Tracker) Ready() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.ready
+}
+
+// Loaded reports whether the dataset has been loaded. Unlike Ready it stays
+// true once the server is marked not ready for shutdown, so requests keep
+// being served while load balancers stop routing to it.
+func (t *Tracker) Loaded() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return !t.loadedAt.IsZero()
+}
+
+// Liveness reports that the process is up. It never depends on the data.
+func (t *Tracker) Liveness() model.HealthStatus {
+	return model.HealthStatus{Status: StatusUp, UptimeSeconds: t.uptime()}
+}
+
+// Readiness reports whether the server is ready, along with the loaded
+// dataset's edition and counts once available.
+func (t *Tracker) Readiness() model.HealthStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	status := model.HealthStatus{Status: StatusUp, UptimeSeconds: t.uptime()}
+	if !t.ready {
+		status.Status = StatusOutOfService
+		status.Error = t.reason
+	}
+	if !t.loadedAt.IsZero() {
+		edition, counts, loadedAt := t.edition, t.counts, t.loadedAt
+		status.Edition = &edition
+		status.Counts = &counts
+		status.LoadedAt = &loadedAt
+		status.LoadDurationMS = t.loadDuration.Milliseconds()
+	}
+	return status
+}
+
+func (t *Tracker) uptime() int64 {
+	return int64(time.Since(t.startedAt) / time.Second)
+}
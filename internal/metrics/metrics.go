@@ -0,0 +1,218 @@
+// Package metrics collects Prometheus metrics for the API: HTTP traffic,
+// rate limit decisions, cache effectiveness and the loaded dataset.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+)
+
+const namespace = "geoid"
+
+// Rate limit tiers.
+const (
+	TierAnonymous = "anonymous"
+	TierAPIKey    = "api_key"
+)
+
+// Rate limit decisions.
+const (
+	DecisionAllowed    = "allowed"
+	DecisionBlocked    = "blocked"
+	DecisionInvalidKey = "invalid_key"
+	// DecisionExpiredKey, DecisionDisabledKey and DecisionOriginDenied
+	// count requests refused because of their configured API key.
+	DecisionExpiredKey   = "expired_key"
+	DecisionDisabledKey  = "disabled_key"
+	DecisionOriginDenied = "origin_denied"
+	// DecisionCostExceeded counts requests costing more than the whole
+	// limit, which are refused without being charged.
+	DecisionCostExceeded = "cost_exceeded"
+	// DecisionFailOpen and DecisionUnavailable count requests allowed and
+	// refused because the limiter store failed.
+	DecisionFailOpen    = "fail_open"
+	DecisionUnavailable = "unavailable"
+)
+
+// Caches whose hit ratio is tracked.
+const (
+	// CacheConditional counts conditional requests answered with 304.
+	CacheConditional = "conditional"
+	// CachePrecompressed counts list responses served from the
+	// precompressed store.
+	CachePrecompressed = "precompressed"
+)
+
+// Route labels for requests answered before reaching a route: unmatched
+// paths, and responses from app-wide middleware such as the rate limiter.
+const (
+	unmatchedRoute  = "unmatched"
+	middlewareRoute = "middleware"
+)
+
+// Registry holds every metric exported by the service, along with the Go
+// runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"method", "route", "status"})
+
+	rateLimitDecisions = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ratelimit_decisions_total",
+		Help:      "Rate limit decisions by tier (anonymous, api_key) and decision (allowed, blocked, cost_exceeded, invalid_key, expired_key, disabled_key, origin_denied, fail_open, unavailable).",
+	}, []string{"tier", "decision"})
+
+	cacheRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by cache (conditional, precompressed) and result (hit, miss).",
+	}, []string{"cache", "result"})
+
+	datasetLoadSeconds = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dataset_load_duration_seconds",
+		Help:      "Time taken to load, validate and precompress the dataset.",
+	})
+
+	datasetRegions = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dataset_regions",
+		Help:      "Number of loaded regions by level.",
+	}, []string{"level"})
+
+	datasetInfo = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dataset_info",
+		Help:      "Edition of the loaded dataset; always 1.",
+	}, []string{"edition", "updated_at"})
+
+	precompressedBytes = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "precompressed_bytes",
+		Help:      "Size of the precompressed region list bodies by content coding.",
+	}, []string{"encoding"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Middleware returns a Fiber handler that records the count and latency of
+// every request. Requests are labelled with the route pattern that handled
+// them (e.g. /v2/states/:id). Responses written by route group middleware,
+// such as precompressed lists, carry the group's path; those written
+// by app-wide middleware (401, 429, 503) are labelled "middleware", and
+// requests matching no route "unmatched".
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fe *fiber.Error
+			if errors.As(err, &fe) {
+				status = fe.Code
+			}
+		}
+		// The method is copied: new series keep their label values, and
+		// Fiber's request strings are reused after the request.
+		labels := []string{strings.Clone(c.Method()), RoutePattern(c, status), strconv.Itoa(status)}
+		httpRequests.WithLabelValues(labels...).Inc()
+		httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// RoutePattern returns the route pattern that answered the request with
+// status, as labelled by Middleware.
+func RoutePattern(c *fiber.Ctx, status int) string {
+	// Only app-wide middleware and the not-found fallback are mounted on
+	// "/" and can answer a request for another path.
+	pattern := c.Route().Path
+	if pattern == "/" && c.Path() != "/" {
+		pattern = middlewareRoute
+		if status == fiber.StatusNotFound {
+			pattern = unmatchedRoute
+		}
+	}
+	return pattern
+}
+
+// RateLimitDecision records one rate limit decision for tier.
+func RateLimitDecision(tier, decision string) {
+	rateLimitDecisions.WithLabelValues(tier, decision).Inc()
+}
+
+// CacheResult records a hit or miss of cache.
+func CacheResult(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// TrackLimiter exports the number of identifiers a rate limiter tier
+// currently holds a window for, read from size at scrape time.
+func TrackLimiter(tier string, size func() int) {
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "ratelimit_active_identifiers",
+		Help:        "Identifiers (client IPs or API keys) with an active rate limit window.",
+		ConstLabels: prometheus.Labels{"tier": tier},
+	}, func() float64 { return float64(size()) })
+}
+
+// SetDataset records the loaded dataset and how long loading took.
+func SetDataset(edition model.Edition, counts model.RegionCounts, loadDuration time.Duration) {
+	datasetLoadSeconds.Set(loadDuration.Seconds())
+	datasetRegions.WithLabelValues("state").Set(float64(counts.States))
+	datasetRegions.WithLabelValues("city").Set(float64(counts.Cities))
+	datasetRegions.WithLabelValues("district").Set(float64(counts.Districts))
+	datasetRegions.WithLabelValues("village").Set(float64(counts.Villages))
+	datasetInfo.Reset()
+	datasetInfo.WithLabelValues(edition.Name, edition.UpdatedAt.Format(time.RFC3339)).Set(1)
+}
+
+// SetPrecompressedBytes records the total size of the precompressed bodies
+// per content coding ("identity" for uncompressed).
+func SetPrecompressedBytes(sizes map[string]int) {
+	for encoding, n := range sizes {
+		precompressedBytes.WithLabelValues(encoding).Set(float64(n))
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/metrics"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
+)
+
+// AccessLog returns a Fiber handler that:
+//  1. Runs the rest of the chain, rendering any error it returns with the
+//     app's error handler so the final status is known.
+//  2. Logs one record per request with the request ID, trace ID, method,
+//     route pattern, path (with NIKs masked), status, latency, client IP,
+//     API key ID (or hash, for unknown keys) and rate limit decision.
+//  3. Logs 5xx responses at error level and everything else at info.
+//
+// It must be registered after the request ID and tracing middleware and
+// before every other middleware.
+func AccessLog(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			if herr := c.App().Config().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		latency := time.Since(start)
+
+		status := c.Response().StatusCode()
+		attrs := []slog.Attr{
+			slog.String("request_id", render.RequestID(c)),
+			slog.String("method", c.Method()),
+			slog.String("route", metrics.RoutePattern(c, status)),
+			slog.String("path", render.RedactPath(c.Path())),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+			slog.String("client_ip", getClientIP(c)),
+		}
+		if traceID := tracing.TraceID(c); traceID != "" {
+			attrs = append(attrs, slog.String("trace_id", traceID))
+		}
+		if id, ok := c.Locals(localsAPIKeyID).(string); ok {
+			attrs = append(attrs, slog.String("api_key", id))
+		} else if apiKey := c.Get(headerAPIKey); apiKey != "" {
+			attrs = append(attrs, slog.String("api_key", HashAPIKey(apiKey)))
+		}
+		if decision, ok := c.Locals(localsRateLimit).(string); ok {
+			attrs = append(attrs, slog.String("rate_limit", decision))
+		}
+
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(c.UserContext(), level, "request", attrs...)
+		return nil
+	}
+}
+
+// HashAPIKey returns a short, stable identifier for apiKey that can be
+// logged without revealing the key: the first 12 hex digits of its SHA-256.
+func HashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:6])
+}
@@ -1,8 +1,16 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // AccessTier defines the rate limit tier for a request.
@@ -15,38 +23,225 @@ const (
 	TierAPIKey
 )
 
-// APIKeyService holds the set of valid API keys loaded from environment.
-// Designed for future migration to a database-backed store.
+var (
+	// ErrAPIKeyExpired is returned for keys past their expiry date.
+	ErrAPIKeyExpired = errors.New("API key expired")
+	// ErrAPIKeyDisabled is returned for keys that have been disabled.
+	ErrAPIKeyDisabled = errors.New("API key disabled")
+	// ErrOriginNotAllowed is returned for browser requests from an origin
+	// the key is not allowed to be used from.
+	ErrOriginNotAllowed = errors.New("origin not allowed for this API key")
+)
+
+// APIKey describes an API key and what it may do. The key itself is only
+// kept as its SHA-256 hash.
+type APIKey struct {
+	// ID names the key in logs and listings.
+	ID    string
+	Owner string
+	// RateLimit is the key's requests per minute; 0 uses the API key tier
+	// limit.
+	RateLimit int
+	// AllowedOrigins restricts browser requests, those sending Origin, to
+	// these origins, written as in CORSConfig. Empty allows any origin.
+	AllowedOrigins []string
+	CreatedAt      time.Time
+	// ExpiresAt is when the key stops working; zero never expires.
+	ExpiresAt time.Time
+	Enabled   bool
+
+	hash [sha256.Size]byte
+}
+
+// Status returns ErrAPIKeyDisabled or ErrAPIKeyExpired when the key cannot
+// be used at now, and nil otherwise.
+func (k APIKey) Status(now time.Time) error {
+	switch {
+	case !k.Enabled:
+		return ErrAPIKeyDisabled
+	case !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt):
+		return ErrAPIKeyExpired
+	}
+	return nil
+}
+
+// AllowsOrigin reports whether a request sending origin may use the key.
+// Requests without an Origin header are always allowed.
+func (k APIKey) AllowsOrigin(origin string) bool {
+	return origin == "" || len(k.AllowedOrigins) == 0 || originAllowed(k.AllowedOrigins, origin)
+}
+
+// APIKeyService holds the API keys loaded from configuration, indexed by
+// the hash of the key.
 type APIKeyService struct {
-	validKeys map[string]struct{}
+	keys   map[[sha256.Size]byte]*APIKey
+	sorted []APIKey // by ID
 }
 
-// NewAPIKeyService creates an APIKeyService by reading the API_KEYS
-// environment variable (comma-separated list of keys).
-// Example: API_KEYS=internal123,partner456,test789
-func NewAPIKeyService() *APIKeyService {
-	keys := make(map[string]struct{})
+// NewAPIKeyService creates an APIKeyService accepting the given keys. It
+// fails when two keys share an ID or a key.
+func NewAPIKeyService(keys []APIKey) (*APIKeyService, error) {
+	s := &APIKeyService{keys: make(map[[sha256.Size]byte]*APIKey, len(keys))}
+	ids := make(map[string]bool, len(keys))
+	for i := range keys {
+		k := &keys[i]
+		if ids[k.ID] {
+			return nil, fmt.Errorf("duplicate API key id %q", k.ID)
+		}
+		if _, ok := s.keys[k.hash]; ok {
+			return nil, fmt.Errorf("API key %q is configured twice", k.ID)
+		}
+		ids[k.ID] = true
+		s.keys[k.hash] = k
+		s.sorted = append(s.sorted, *k)
+	}
+	slices.SortFunc(s.sorted, func(a, b APIKey) int { return strings.Compare(a.ID, b.ID) })
+	return s, nil
+}
+
+// APIKeysFromList returns the keys configured by API_KEYS, a
+// comma-separated list of keys such as "internal123,partner456", which are
+// enabled, never expire and use the API key tier limit. Their ID is the
+// HashAPIKey of the key.
+func APIKeysFromList(keys []string) []APIKey {
+	out := make([]APIKey, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, APIKey{ID: HashAPIKey(key), Enabled: true, hash: sha256.Sum256([]byte(key))})
+	}
+	return out
+}
 
-	rawKeys := os.Getenv("API_KEYS")
-	if rawKeys != "" {
-		for _, k := range strings.Split(rawKeys, ",") {
-			trimmed := strings.TrimSpace(k)
-			if trimmed != "" {
-				keys[trimmed] = struct{}{}
+// apiKeysFile is the YAML keys file read by LoadAPIKeys.
+type apiKeysFile struct {
+	Keys []struct {
+		ID    string `yaml:"id"`
+		Owner string `yaml:"owner"`
+		// Key is the key itself; KeySHA256 its hex SHA-256 hash, so that
+		// the file need not hold the key.
+		Key            string    `yaml:"key"`
+		KeySHA256      string    `yaml:"key_sha256"`
+		RateLimit      int       `yaml:"rate_limit"`
+		AllowedOrigins []string  `yaml:"allowed_origins"`
+		CreatedAt      time.Time `yaml:"created_at"`
+		ExpiresAt      time.Time `yaml:"expires_at"`
+		// Enabled defaults to true.
+		Enabled *bool `yaml:"enabled"`
+	} `yaml:"keys"`
+}
+
+// LoadAPIKeys reads API keys from the YAML file at path:
+//
+//	keys:
+//	  - id: acme
+//	    owner: ACME Corp
+//	    key_sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+//	    rate_limit: 5000
+//	    allowed_origins: [https://acme.example]
+//	    created_at: 2026-01-15
+//	    expires_at: 2027-01-15
+//	    enabled: true
+//
+// Each key needs an id and either key or key_sha256. The error names every
+// invalid key.
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file apiKeysFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	keys := make([]APIKey, 0, len(file.Keys))
+	var errs []error
+	for i, fk := range file.Keys {
+		k := APIKey{
+			ID:             fk.ID,
+			Owner:          fk.Owner,
+			RateLimit:      fk.RateLimit,
+			AllowedOrigins: fk.AllowedOrigins,
+			CreatedAt:      fk.CreatedAt,
+			ExpiresAt:      fk.ExpiresAt,
+			Enabled:        fk.Enabled == nil || *fk.Enabled,
+		}
+		invalid := func(format string, args ...any) {
+			name := fmt.Sprintf("key %d", i+1)
+			if k.ID != "" {
+				name = fmt.Sprintf("key %q", k.ID)
+			}
+			errs = append(errs, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+		}
+
+		if k.ID == "" {
+			invalid("id must not be empty")
+		}
+		switch {
+		case (fk.Key == "") == (fk.KeySHA256 == ""):
+			invalid("exactly one of key and key_sha256 must be set")
+		case fk.Key != "":
+			k.hash = sha256.Sum256([]byte(fk.Key))
+		default:
+			if sum, err := hex.DecodeString(fk.KeySHA256); err != nil || len(sum) != sha256.Size {
+				invalid("key_sha256 must be 64 hex digits")
+			} else {
+				copy(k.hash[:], sum)
+			}
+		}
+		if k.RateLimit < 0 {
+			invalid("rate_limit must not be negative, got %d", k.RateLimit)
+		}
+		for _, origin := range k.AllowedOrigins {
+			if err := validateOrigin(origin); err != nil {
+				invalid("%v", err)
 			}
 		}
+		if !k.ExpiresAt.IsZero() && !k.CreatedAt.IsZero() && !k.ExpiresAt.After(k.CreatedAt) {
+			invalid("expires_at must be after created_at")
+		}
+		keys = append(keys, k)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s: %w", path, errors.Join(errs...))
 	}
+	return keys, nil
+}
 
-	return &APIKeyService{validKeys: keys}
+// Lookup returns the configured key matching key. It fails with
+// ErrInvalidAPIKey for unknown keys and with the error of APIKey.Status for
+// keys that cannot be used now.
+func (s *APIKeyService) Lookup(key string) (APIKey, error) {
+	k, ok := s.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+	return *k, k.Status(time.Now())
 }
 
-// IsValid reports whether the given API key exists in the key set.
+// IsValid reports whether the given API key is configured and usable.
 func (s *APIKeyService) IsValid(key string) bool {
-	_, ok := s.validKeys[key]
-	return ok
+	_, err := s.Lookup(key)
+	return err == nil
 }
 
 // HasKeys reports whether any API keys have been configured.
 func (s *APIKeyService) HasKeys() bool {
-	return len(s.validKeys) > 0
+	return len(s.keys) > 0
+}
+
+// Keys returns the configured keys sorted by ID.
+func (s *APIKeyService) Keys() []APIKey {
+	return slices.Clone(s.sorted)
+}
+
+// RateLimits returns the distinct per-key rate limits, in ascending order.
+func (s *APIKeyService) RateLimits() []int {
+	var limits []int
+	for _, k := range s.sorted {
+		if k.RateLimit > 0 && !slices.Contains(limits, k.RateLimit) {
+			limits = append(limits, k.RateLimit)
+		}
+	}
+	slices.Sort(limits)
+	return limits
 }
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/compress"
+	"github.com/ikhsanfalakh/geo-id/internal/metrics"
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+)
+
+// EditionSource provides the edition of the dataset being served.
+// service.LocationService implements it.
+type EditionSource interface {
+	Edition() (*model.Edition, error)
+}
+
+// FormatFunc returns the format a request is answered in, or false when
+// the request cannot be served in any. render.Negotiate is the FormatFunc
+// of routes serving the negotiated envelope.
+type FormatFunc func(c *fiber.Ctx) (string, bool)
+
+// HTTPCache returns a Fiber handler that adds HTTP caching validators to
+// successful GET and HEAD responses:
+//  1. ETag derived from the dataset edition, the request URI, the response
+//     format given by formatOf and the content coding, so it changes only
+//     with the data and differs between representations.
+//  2. Last-Modified set to the dataset edition's update time.
+//  3. Cache-Control with the given max-age (no-cache when zero).
+//
+// Conditional requests (If-None-Match, If-Modified-Since) are evaluated
+// once the handler has answered 200, and the response is then replaced
+// with a 304, so requests for regions that do not exist still get their
+// 404.
+func HTTPCache(editions EditionSource, maxAge time.Duration, formatOf FormatFunc) fiber.Handler {
+	cacheControl := "no-cache"
+	if maxAge > 0 {
+		cacheControl = "public, max-age=" + itoa(int(maxAge/time.Second))
+	}
+
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+		format, ok := formatOf(c)
+		if !ok {
+			return c.Next()
+		}
+		edition, err := editions.Edition()
+		if err != nil {
+			return c.Next()
+		}
+		lastModified := edition.UpdatedAt.UTC().Truncate(time.Second)
+		lastModifiedHeader := lastModified.Format(http.TimeFormat)
+
+		encoding := compress.Negotiate(c.Get(fiber.HeaderAcceptEncoding))
+		etag := computeETag(*edition, c.OriginalURL(), format, encoding)
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() != fiber.StatusOK {
+			return nil
+		}
+		setCacheHeaders(c, etag, lastModifiedHeader, cacheControl)
+
+		if c.Get(fiber.HeaderIfNoneMatch) == "" && c.Get(fiber.HeaderIfModifiedSince) == "" {
+			return nil
+		}
+		hit := notModified(c, etag, lastModified)
+		metrics.CacheResult(metrics.CacheConditional, hit)
+		if hit {
+			// Drops the body, streamed exports included.
+			c.Response().ResetBody()
+			c.Status(fiber.StatusNotModified)
+		}
+		return nil
+	}
+}
+
+// computeETag hashes the inputs that determine a response body.
+func computeETag(edition model.Edition, uri, format, encoding string) string {
+	h := sha256.New()
+	h.Write([]byte(edition.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(edition.UpdatedAt.UTC().Format(time.RFC3339)))
+	h.Write([]byte{0})
+	h.Write([]byte(uri))
+	h.Write([]byte{0})
+	h.Write([]byte(format))
+	h.Write([]byte{0})
+	h.Write([]byte(encoding))
+	return `"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`
+}
+
+// notModified evaluates the request's conditional headers against a
+// representation that exists: it is only called for 200 responses, which
+// is what makes "If-None-Match: *" match. If-None-Match takes precedence
+// over If-Modified-Since, as required by RFC 9110.
+func notModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if inm := c.Get(fiber.HeaderIfNoneMatch); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := c.Get(fiber.HeaderIfModifiedSince); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !lastModified.After(t)
+		}
+	}
+	return false
+}
+
+func setCacheHeaders(c *fiber.Ctx, etag, lastModified, cacheControl string) {
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, lastModified)
+	c.Set(fiber.HeaderCacheControl, cacheControl)
+	c.Vary(fiber.HeaderAccept, fiber.HeaderAcceptEncoding)
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+)
+
+type testEdition model.Edition
+
+func (e testEdition) Edition() (*model.Edition, error) {
+	edition := model.Edition(e)
+	return &edition, nil
+}
+
+// cacheApp serves region 32 and a streamed export behind HTTPCache; other
+// regions are 404.
+func cacheApp(updatedAt time.Time) *fiber.App {
+	editions := testEdition{Name: "test", UpdatedAt: updatedAt}
+	app := fiber.New()
+	app.Get("/regions/:code", HTTPCache(editions, time.Minute, render.Negotiate), func(c *fiber.Ctx) error {
+		if c.Params("code") != "32" {
+			return c.Status(fiber.StatusNotFound).SendString("region not found")
+		}
+		return c.SendString("Jawa Barat")
+	})
+	app.Get("/export", HTTPCache(editions, time.Minute, render.Negotiate), func(c *fiber.Ctx) error {
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			w.WriteString("code,name\n32,Jawa Barat\n")
+		})
+		return nil
+	})
+	return app
+}
+
+func TestHTTPCacheConditionalRequests(t *testing.T) {
+	updatedAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	app := cacheApp(updatedAt)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/regions/32", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if resp.StatusCode != fiber.StatusOK || etag == "" {
+		t.Fatalf("got status %d, ETag %q; want 200 with an ETag", resp.StatusCode, etag)
+	}
+
+	later := updatedAt.Add(time.Hour).Format(http.TimeFormat)
+	earlier := updatedAt.Add(-time.Hour).Format(http.TimeFormat)
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		value      string
+		wantStatus int
+		wantBody   string
+	}{
+		{"matching ETag", "/regions/32", fiber.HeaderIfNoneMatch, etag, fiber.StatusNotModified, ""},
+		{"weak matching ETag", "/regions/32", fiber.HeaderIfNoneMatch, `"other", W/` + etag, fiber.StatusNotModified, ""},
+		{"other ETag", "/regions/32", fiber.HeaderIfNoneMatch, `"other"`, fiber.StatusOK, "Jawa Barat"},
+		{"any ETag", "/regions/32", fiber.HeaderIfNoneMatch, "*", fiber.StatusNotModified, ""},
+		{"not modified since", "/regions/32", fiber.HeaderIfModifiedSince, later, fiber.StatusNotModified, ""},
+		{"modified since", "/regions/32", fiber.HeaderIfModifiedSince, earlier, fiber.StatusOK, "Jawa Barat"},
+		// RFC 9110: * only matches a current representation.
+		{"any ETag of a missing region", "/regions/99", fiber.HeaderIfNoneMatch, "*", fiber.StatusNotFound, "region not found"},
+		{"missing region not modified since", "/regions/00.00", fiber.HeaderIfModifiedSince, later, fiber.StatusNotFound, "region not found"},
+		{"streamed body", "/export", fiber.HeaderIfModifiedSince, later, fiber.StatusNotModified, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, tt.path, nil)
+			req.Header.Set(tt.header, tt.value)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+				t.Fatalf("got %d %q, want %d %q", resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+			if tt.wantStatus == fiber.StatusNotFound && resp.Header.Get(fiber.HeaderETag) != "" {
+				t.Fatal("404 carries an ETag")
+			}
+			if tt.wantStatus == fiber.StatusNotModified && resp.Header.Get(fiber.HeaderETag) == "" {
+				t.Fatal("304 carries no ETag")
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/compress"
+	"github.com/ikhsanfalakh/geo-id/internal/metrics"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+)
+
+// compressibleTypes are the Content-Type prefixes worth compressing.
+var compressibleTypes = []string{
+	"application/json",
+	"application/xml",
+	"application/msgpack",
+	"application/x-ndjson",
+	"text/",
+}
+
+// Precompressed holds JSON response bodies compressed ahead of time in every
+// supported coding, keyed by request path. It is filled once at startup and
+// only read afterwards; Add is safe for concurrent use.
+type Precompressed struct {
+	mu     sync.Mutex
+	bodies map[string]map[string][]byte
+	sizes  map[string]int
+}
+
+// NewPrecompressed returns an empty store.
+func NewPrecompressed() *Precompressed {
+	return &Precompressed{bodies: map[string]map[string][]byte{}, sizes: map[string]int{}}
+}
+
+// Add compresses body with every supported coding and stores the results,
+// along with the uncompressed body, under each of paths.
+func (p *Precompressed) Add(body []byte, paths ...string) error {
+	entry := map[string][]byte{compress.Identity: body}
+	for _, enc := range compress.Encodings {
+		encoded, err := compress.Encode(body, enc)
+		if err != nil {
+			return err
+		}
+		entry[enc] = encoded
+	}
+	p.mu.Lock()
+	for _, path := range paths {
+		p.bodies[path] = entry
+	}
+	for enc, encoded := range entry {
+		p.sizes[enc] += len(encoded)
+	}
+	p.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of stored paths.
+func (p *Precompressed) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.bodies)
+}
+
+// Sizes returns the total size of the stored bodies per coding, counting
+// bodies shared by several paths once. The uncompressed size is reported
+// as "identity".
+func (p *Precompressed) Sizes() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sizes := make(map[string]int, len(p.sizes))
+	for enc, n := range p.sizes {
+		if enc == compress.Identity {
+			enc = "identity"
+		}
+		sizes[enc] = n
+	}
+	return sizes
+}
+
+// Compress returns a Fiber handler that compresses responses with the best
+// coding the client accepts (br, zstd or gzip):
+//  1. GET and HEAD requests for a path in store, without a query string and
+//     negotiated as JSON, are answered from store without running the handler.
+//  2. Other responses of at least minSize bytes with a compressible content
+//     type are compressed on the fly. Streamed bodies are left alone.
+//
+// store may be nil.
+func Compress(store *Precompressed, minSize int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Vary(fiber.HeaderAcceptEncoding)
+		encoding := compress.Negotiate(c.Get(fiber.HeaderAcceptEncoding))
+
+		body, ok := lookupPrecompressed(c, store, encoding)
+		if store != nil {
+			metrics.CacheResult(metrics.CachePrecompressed, ok)
+		}
+		if ok {
+			c.Vary(fiber.HeaderAccept)
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			if encoding != compress.Identity {
+				c.Set(fiber.HeaderContentEncoding, encoding)
+			}
+			// The stored slice is shared between requests, so it must be
+			// copied into the response rather than set as its raw body.
+			return c.Status(fiber.StatusOK).Send(body)
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if encoding == compress.Identity {
+			return nil
+		}
+
+		resp := c.Response()
+		if resp.IsBodyStream() || len(resp.Header.Peek(fiber.HeaderContentEncoding)) > 0 ||
+			len(resp.Body()) < minSize || !compressible(string(resp.Header.ContentType())) {
+			return nil
+		}
+
+		var buf bytes.Buffer
+		w, err := compress.NewWriter(&buf, encoding)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(resp.Body()); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentEncoding, encoding)
+		resp.SetBodyRaw(buf.Bytes())
+		return nil
+	}
+}
+
+// lookupPrecompressed returns the stored body for the request, if any.
+func lookupPrecompressed(c *fiber.Ctx, store *Precompressed, encoding string) ([]byte, bool) {
+	if store == nil || (c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead) {
+		return nil, false
+	}
+	if len(c.Request().URI().QueryString()) > 0 {
+		return nil, false
+	}
+	entry, ok := store.bodies[c.Path()]
+	if !ok {
+		return nil, false
+	}
+	if format, ok := render.Negotiate(c); !ok || format != render.FormatJSON {
+		return nil, false
+	}
+	return entry[encoding], true
+}
+
+func compressible(contentType string) bool {
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
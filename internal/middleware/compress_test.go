@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+
+	"github.com/ikhsanfalakh/geo-id/internal/compress"
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+// compressMinSize is the default COMPRESS_MIN_SIZE.
+const compressMinSize = 1024
+
+// listBody returns the /v1 JSON body of a region list, as stored in the
+// precompressed lists.
+func listBody(b *testing.B, regions []model.Region, err error) []byte {
+	b.Helper()
+	if err != nil {
+		b.Fatal(err)
+	}
+	result, err := service.ListQuery{}.Apply(regions)
+	if err != nil {
+		b.Fatal(err)
+	}
+	envelope, _ := render.Envelope(render.V1, model.NewPaginatedResponse(result.Items, result.Pagination), "")
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return body
+}
+
+// BenchmarkCompress measures the Compress middleware answering region
+// lists from the precompressed store and compressing them on the fly, in
+// every coding, reporting the size of the response body as B/response:
+//
+//	go test -run '^$' -bench 'Compress|Encode|NewWriter' ./internal/compress ./internal/middleware
+func BenchmarkCompress(b *testing.B) {
+	svc := service.NewLocationService("../../data")
+	ctx := context.Background()
+	states, err := svc.GetStates(ctx)
+	statesBody := listBody(b, states, err)
+	villages, err := svc.GetVillages(ctx, "95.06.03")
+	villagesBody := listBody(b, villages, err)
+
+	lists := []struct {
+		name, path string
+		body       []byte
+	}{
+		{"states", "/v1/states", statesBody},
+		{"villages", "/v1/districts/95.06.03/villages", villagesBody},
+	}
+	for _, list := range lists {
+		store := NewPrecompressed()
+		if err := store.Add(list.body, list.path); err != nil {
+			b.Fatal(err)
+		}
+		for _, mode := range []struct {
+			name  string
+			store *Precompressed
+		}{{"precompressed", store}, {"on_the_fly", nil}} {
+			app := fiber.New()
+			app.Use(Compress(mode.store, compressMinSize))
+			app.Get(list.path, func(c *fiber.Ctx) error {
+				c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+				return c.Send(list.body)
+			})
+			handler := app.Handler()
+
+			for _, enc := range append([]string{compress.Identity}, compress.Encodings...) {
+				name := enc
+				if enc == compress.Identity {
+					name = "identity"
+				}
+				b.Run(list.name+"/"+mode.name+"/"+name, func(b *testing.B) {
+					var fctx fasthttp.RequestCtx
+					fctx.Request.SetRequestURI(list.path)
+					fctx.Request.Header.SetMethod(fiber.MethodGet)
+					if enc != compress.Identity {
+						fctx.Request.Header.Set(fiber.HeaderAcceptEncoding, enc)
+					}
+					b.ReportAllocs()
+					for range b.N {
+						fctx.Response.Reset()
+						handler(&fctx)
+					}
+					if got := string(fctx.Response.Header.Peek(fiber.HeaderContentEncoding)); got != enc {
+						b.Fatalf("got Content-Encoding %q, want %q", got, enc)
+					}
+					b.ReportMetric(float64(len(fctx.Response.Body())), "B/response")
+				})
+			}
+		}
+	}
+}
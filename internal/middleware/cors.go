@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// Defaults for the CORS settings left empty in CORSConfig.
+var (
+	DefaultCORSMethods = []string{fiber.MethodGet, fiber.MethodPost, fiber.MethodHead}
+	DefaultCORSHeaders = []string{
+		fiber.HeaderAccept,
+		fiber.HeaderContentType,
+		headerAPIKey,
+		fiber.HeaderXRequestID,
+		fiber.HeaderIfNoneMatch,
+		fiber.HeaderIfModifiedSince,
+		"traceparent",
+		"tracestate",
+	}
+)
+
+// corsExposedHeaders lists the response headers, beyond the CORS-safelisted
+// ones, that browsers let scripts read.
+var corsExposedHeaders = []string{
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	fiber.HeaderRetryAfter,
+	fiber.HeaderXRequestID,
+	fiber.HeaderETag,
+	fiber.HeaderContentDisposition,
+	"X-Data-Edition",
+	"X-Data-Updated-At",
+	"X-Pagination-Total",
+	"X-Pagination-Limit",
+	"X-Pagination-Offset",
+	"X-Pagination-Next-Cursor",
+}
+
+// CORSConfig configures the CORS middleware.
+type CORSConfig struct {
+	// AllowedOrigins lists the origins browsers may call the API from, such
+	// as "https://app.example.com". "https://*.example.com" matches every
+	// subdomain of example.com and "*" matches any origin.
+	AllowedOrigins []string
+	// AllowedMethods and AllowedHeaders are answered to preflight requests.
+	// Empty lists use DefaultCORSMethods and DefaultCORSHeaders.
+	AllowedMethods []string
+	AllowedHeaders []string
+	// AllowCredentials lets browsers send cookies and HTTP authentication.
+	// It cannot be combined with the "*" origin.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response. Zero
+	// leaves it to the browser.
+	MaxAge time.Duration
+}
+
+// CORS returns a Fiber handler that:
+//  1. Sets Access-Control-Allow-Origin on responses to allowed origins, with
+//     Vary: Origin unless every origin is allowed.
+//  2. Exposes the X-RateLimit-*, Retry-After, X-Request-ID, ETag, export and
+//     pagination headers to scripts.
+//  3. Answers preflight requests with 204 No Content without running the
+//     rest of the chain, so they never count against the rate limit.
+//
+// It returns the error from Validate for an invalid cfg. It must be
+// registered before the rate limit and readiness middleware.
+func CORS(cfg CORSConfig) (fiber.Handler, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+	return cors.New(cors.Config{
+		AllowOrigins:     strings.Join(cfg.AllowedOrigins, ","),
+		AllowMethods:     strings.ToUpper(strings.Join(methods, ",")),
+		AllowHeaders:     strings.Join(headers, ","),
+		AllowCredentials: cfg.AllowCredentials,
+		ExposeHeaders:    strings.Join(corsExposedHeaders, ","),
+		MaxAge:           int(cfg.MaxAge / time.Second),
+	}), nil
+}
+
+// Validate reports an error when no origin is allowed, an origin is
+// malformed, or credentials are allowed for every origin.
+func (cfg CORSConfig) Validate() error {
+	if len(cfg.AllowedOrigins) == 0 {
+		return errors.New("no allowed origins")
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			if cfg.AllowCredentials {
+				return errors.New(`credentials cannot be allowed for origin "*"`)
+			}
+			if len(cfg.AllowedOrigins) > 1 {
+				return errors.New(`origin "*" cannot be combined with other origins`)
+			}
+			continue
+		}
+		if err := validateOrigin(origin); err != nil {
+			return err
+		}
+	}
+	if cfg.MaxAge < 0 {
+		return fmt.Errorf("invalid max age %s", cfg.MaxAge)
+	}
+	return nil
+}
+
+// validateOrigin checks that origin is a scheme and host, optionally with a
+// port, and that a wildcard only stands for the leftmost subdomain label.
+func validateOrigin(origin string) error {
+	host := origin
+	if i := strings.Index(origin, "://*."); i != -1 {
+		host = origin[:i+3] + origin[i+5:]
+	}
+	u, err := url.Parse(host)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		strings.Contains(u.Host, "*") || (u.Path != "" && u.Path != "/") ||
+		u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("invalid origin %q: want scheme://host[:port], optionally with a *. subdomain wildcard", origin)
+	}
+	return nil
+}
+
+// originAllowed reports whether origin matches one of patterns, written as
+// CORSConfig.AllowedOrigins.
+func originAllowed(patterns []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSuffix(pattern, "/"))
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+		scheme, domain, ok := strings.Cut(pattern, "://*.")
+		if ok && strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GCRALimiter is an in-memory rate limiter using the generic cell rate
+// algorithm, equivalent to a token bucket holding limit requests that
+// refills one request every window/limit. Unlike the sliding log of
+// RateLimiter it keeps a single timestamp per identifier, checks in
+// constant time and spreads identifiers over sharded locks.
+//
+// A client that was idle may send the full limit at once; after that,
+// requests are admitted at the steady rate instead of in a new burst when
+// the window slides.
+type GCRALimiter struct {
+	// state holds the theoretical arrival time of each identifier, in Unix
+	// nanoseconds: the time at which its bucket is full again.
+	state    *shardedState[int64]
+	limit    int
+	window   time.Duration
+	interval time.Duration // time to refill one request, window/limit
+}
+
+// NewGCRALimiter creates a GCRALimiter admitting limit requests per window.
+// It panics unless limit is at least 1 and window is positive.
+func NewGCRALimiter(limit int, window time.Duration) *GCRALimiter {
+	if limit < 1 || window <= 0 {
+		panic(fmt.Sprintf("middleware: NewGCRALimiter needs a limit of at least 1 and a positive window, got %d per %v", limit, window))
+	}
+	return &GCRALimiter{
+		state:    newShardedState(func(tat, now int64) bool { return tat <= now }),
+		limit:    limit,
+		window:   window,
+		interval: window / time.Duration(limit),
+	}
+}
+
+// CheckN charges cost requests to identifier. ResetAt is when the bucket
+// is full again for admitted requests, and when the request would be
+// admitted for rejected ones; it is zero when cost is above the limit and
+// the request could never be admitted. It never fails.
+func (l *GCRALimiter) CheckN(_ context.Context, identifier string, cost int) (LimitResult, error) {
+	if cost < 1 {
+		cost = 1
+	}
+	if cost > l.limit {
+		return LimitResult{Limit: l.limit}, nil
+	}
+	sh := l.state.lock(identifier)
+	defer sh.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	from := max(sh.entries[identifier], now)
+	next := from + int64(cost)*int64(l.interval)
+	if allowAt := next - int64(l.window); allowAt > now {
+		return LimitResult{
+			Allowed:   false,
+			Limit:     l.limit,
+			Remaining: l.remaining(now, from),
+			ResetAt:   time.Unix(0, allowAt),
+		}, nil
+	}
+	sh.entries[identifier] = next
+	return LimitResult{
+		Allowed:   true,
+		Limit:     l.limit,
+		Remaining: l.remaining(now, next),
+		ResetAt:   time.Unix(0, next),
+	}, nil
+}
+
+// remaining returns how many requests the bucket holds at now when it is
+// full again at tat.
+func (l *GCRALimiter) remaining(now, tat int64) int {
+	return int((now + int64(l.window) - tat) / int64(l.interval))
+}
+
+// Len returns the number of identifiers whose bucket is not full, up to a
+// minute after it refilled.
+func (l *GCRALimiter) Len() int {
+	return l.state.Len()
+}
+
+// Stop ends the background cleanup. It is safe to call more than once.
+func (l *GCRALimiter) Stop() {
+	l.state.Stop()
+}
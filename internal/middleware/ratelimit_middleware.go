@@ -1,10 +1,19 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/metrics"
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
 )
 
 const (
@@ -13,11 +22,43 @@ const (
 	// DefaultLimitAPIKey is the fallback cap per minute for authenticated API key clients.
 	DefaultLimitAPIKey = 1000
 
-	// windowDuration is the sliding window interval.
-	windowDuration = time.Minute
+	// RateLimitWindow is the sliding window interval of every limiter.
+	RateLimitWindow = time.Minute
+
+	// unavailableLogInterval throttles the warning logged while the limiter
+	// store fails, which would otherwise repeat on every request.
+	unavailableLogInterval = 10 * time.Second
+	// unavailableRetryAfter is the Retry-After value, in seconds, sent when
+	// requests are refused because the limiter store fails.
+	unavailableRetryAfter = "1"
 
 	// headerAPIKey is the name of the request header carrying the API key.
 	headerAPIKey = "X-API-KEY"
+
+	// localsRateLimit is the Locals key holding the rate limit decision
+	// for the access log.
+	localsRateLimit = "ratelimit"
+	// localsAPIKeyID is the Locals key holding the ID of the request's
+	// API key for the access log.
+	localsAPIKeyID = "apikey_id"
+	// decisionExempt marks requests to excluded paths.
+	decisionExempt = "exempt"
+)
+
+var (
+	// ErrInvalidAPIKey is returned for API keys that are not configured.
+	// Keys that are configured but cannot be used fail with the errors of
+	// APIKey.Status and ErrOriginNotAllowed instead.
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrRateLimitExceeded is returned when a request does not fit in the limit.
+	ErrRateLimitExceeded = errors.New("too many requests")
+	// ErrCostExceedsLimit is returned when a request costs more than the
+	// whole limit, e.g. a batch too large for the caller's tier, so that it
+	// would never be admitted.
+	ErrCostExceedsLimit = errors.New("request cost exceeds the rate limit")
+	// ErrLimiterUnavailable is returned when the limit cannot be checked
+	// and the limiter fails closed.
+	ErrLimiterUnavailable = errors.New("rate limiter unavailable")
 )
 
 // excludedPrefixes lists URL path prefixes that are exempt from rate limiting.
@@ -26,33 +67,65 @@ var excludedPrefixes = []string{
 	"/swagger",
 	"/actuator/health",
 	"/assets",
+	"/metrics",
 }
 
 // RateLimitConfig holds the dependencies for the rate limit middleware.
 type RateLimitConfig struct {
 	APIKeyService    *APIKeyService
-	AnonymousLimiter *RateLimiter
-	APIKeyLimiter    *RateLimiter
+	AnonymousLimiter Limiter
+	// APIKeyLimiter limits the keys without a rate limit of their own.
+	APIKeyLimiter Limiter
+	// KeyLimiters limit the keys with a rate limit of their own, by that
+	// limit (see APIKeyService.RateLimits). Keys whose limit is missing
+	// fall back to APIKeyLimiter.
+	KeyLimiters map[int]Limiter
+
+	// Cost optionally weighs a request as several requests against the
+	// limit (e.g. batch lookups). Nil, or a result below 1, counts as 1.
+	Cost func(c *fiber.Ctx) int
+
+	// FailOpen allows requests when a limiter fails, e.g. because Redis is
+	// unreachable; otherwise they are refused with ErrLimiterUnavailable.
+	FailOpen bool
+
+	lastUnavailableLog atomic.Int64 // Unix nanoseconds
 }
 
-// NewRateLimitConfig constructs the config with the given per-minute limits.
-// limitAnonymous and limitAPIKey are typically read from environment variables
-// (RATE_LIMIT_ANONYMOUS, RATE_LIMIT_API_KEY) and fall back to the defaults
-// (DefaultLimitAnonymous, DefaultLimitAPIKey) when unset.
+// NewRateLimitConfig constructs the config with in-memory limiters of the
+// given per-minute limits. limitAnonymous and limitAPIKey are typically read
+// from environment variables (RATE_LIMIT_ANONYMOUS, RATE_LIMIT_API_KEY) and
+// fall back to the defaults (DefaultLimitAnonymous, DefaultLimitAPIKey) when
+// unset. Set the limiter fields directly to use another store, and
+// KeyLimiters for keys with a rate limit of their own.
 func NewRateLimitConfig(apiKeySvc *APIKeyService, limitAnonymous, limitAPIKey int) *RateLimitConfig {
 	return &RateLimitConfig{
 		APIKeyService:    apiKeySvc,
-		AnonymousLimiter: NewRateLimiter(limitAnonymous, windowDuration),
-		APIKeyLimiter:    NewRateLimiter(limitAPIKey, windowDuration),
+		AnonymousLimiter: NewRateLimiter(limitAnonymous, RateLimitWindow),
+		APIKeyLimiter:    NewRateLimiter(limitAPIKey, RateLimitWindow),
+	}
+}
+
+// Stop stops the background work of every limiter.
+func (cfg *RateLimitConfig) Stop() {
+	cfg.AnonymousLimiter.Stop()
+	cfg.APIKeyLimiter.Stop()
+	for _, limiter := range cfg.KeyLimiters {
+		limiter.Stop()
 	}
 }
 
 // RateLimitMiddleware returns a Fiber handler that:
 //  1. Skips excluded paths (docs, health, static assets).
-//  2. Validates X-API-KEY header if present.
+//  2. Validates X-API-KEY header if present: the key must be configured,
+//     enabled, unexpired and allowed for the request's Origin.
 //  3. Applies the correct rate limiter (anonymous or API-key tier).
 //  4. Injects X-RateLimit-* response headers.
-//  5. Returns 429 when the limit is exceeded, 401 for invalid API keys.
+//  5. Returns 429 when the limit is exceeded, 413 when the request costs
+//     more than the whole limit, 401 for unknown or expired API keys, 403
+//     for disabled keys or disallowed origins and 503 when the limiter
+//     fails closed.
+//  6. Records the decision for the access log.
 func RateLimitMiddleware(cfg *RateLimitConfig) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		path := c.Path()
@@ -60,45 +133,161 @@ func RateLimitMiddleware(cfg *RateLimitConfig) fiber.Handler {
 		// Skip rate limiting for excluded paths.
 		for _, prefix := range excludedPrefixes {
 			if strings.HasPrefix(path, prefix) {
+				c.Locals(localsRateLimit, decisionExempt)
 				return c.Next()
 			}
 		}
 
-		rawKey := c.Get(headerAPIKey)
-
-		// --- API Key branch ---
-		if rawKey != "" {
-			if !cfg.APIKeyService.IsValid(rawKey) {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"success": false,
-					"error": fiber.Map{
-						"code":    "INVALID_API_KEY",
-						"message": "Invalid API key",
-					},
-				})
-			}
-
-			result := cfg.APIKeyLimiter.Check(rawKey)
+		req := CheckRequest{
+			APIKey:   c.Get(headerAPIKey),
+			ClientIP: getClientIP(c),
+			Origin:   c.Get(fiber.HeaderOrigin),
+			Cost:     cfg.cost(c),
+		}
+		result, key, err := cfg.Check(c.UserContext(), req)
+		if key.ID != "" {
+			c.Locals(localsAPIKeyID, key.ID)
+		}
+		switch {
+		case errors.Is(err, ErrInvalidAPIKey):
+			c.Locals(localsRateLimit, metrics.DecisionInvalidKey)
+			return errorResponse(c, fiber.StatusUnauthorized, "INVALID_API_KEY", "Invalid API key", err)
+		case errors.Is(err, ErrAPIKeyExpired):
+			c.Locals(localsRateLimit, metrics.DecisionExpiredKey)
+			return errorResponse(c, fiber.StatusUnauthorized, "API_KEY_EXPIRED", "API key expired", err)
+		case errors.Is(err, ErrAPIKeyDisabled):
+			c.Locals(localsRateLimit, metrics.DecisionDisabledKey)
+			return errorResponse(c, fiber.StatusForbidden, "API_KEY_DISABLED", "API key disabled", err)
+		case errors.Is(err, ErrOriginNotAllowed):
+			c.Locals(localsRateLimit, metrics.DecisionOriginDenied)
+			return errorResponse(c, fiber.StatusForbidden, "ORIGIN_NOT_ALLOWED", "Origin not allowed for this API key", err)
+		case errors.Is(err, ErrRateLimitExceeded):
+			c.Locals(localsRateLimit, metrics.DecisionBlocked)
 			setRateLimitHeaders(c, result)
+			return rateLimitExceededResponse(c)
+		case errors.Is(err, ErrCostExceedsLimit):
+			c.Locals(localsRateLimit, metrics.DecisionCostExceeded)
+			return errorResponse(c, fiber.StatusRequestEntityTooLarge, "COST_EXCEEDS_LIMIT", "Request costs more than the rate limit allows", err)
+		case errors.Is(err, ErrLimiterUnavailable):
+			c.Locals(localsRateLimit, metrics.DecisionUnavailable)
+			c.Set(fiber.HeaderRetryAfter, unavailableRetryAfter)
+			return errorResponse(c, fiber.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Rate limiter unavailable", err)
+		}
 
-			if !result.Allowed {
-				return rateLimitExceededResponse(c)
-			}
-
+		if result.Limit == 0 {
+			// The limiter failed open; there is no limit state to report.
+			c.Locals(localsRateLimit, metrics.DecisionFailOpen)
 			return c.Next()
 		}
-
-		// --- Anonymous branch ---
-		clientIP := getClientIP(c)
-		result := cfg.AnonymousLimiter.Check(clientIP)
+		c.Locals(localsRateLimit, metrics.DecisionAllowed)
 		setRateLimitHeaders(c, result)
+		return c.Next()
+	}
+}
 
-		if !result.Allowed {
-			return rateLimitExceededResponse(c)
+// CheckRequest identifies the caller of a request to Check.
+type CheckRequest struct {
+	// APIKey is the key sent by the caller, if any.
+	APIKey string
+	// ClientIP identifies anonymous callers.
+	ClientIP string
+	// Origin is the Origin header of browser requests.
+	Origin string
+	// Cost is the weight of the request against the limit.
+	Cost int
+}
+
+// Check validates the API key of req, if present, and charges req.Cost
+// requests to the matching limiter: the key's own limit or the API key tier
+// for valid keys, or the anonymous tier keyed by the client IP. It returns
+// the configured key, once it is found, and fails with:
+//   - ErrInvalidAPIKey for unknown keys, and ErrAPIKeyExpired,
+//     ErrAPIKeyDisabled or ErrOriginNotAllowed for keys that cannot be
+//     used for the request.
+//   - ErrRateLimitExceeded, along with the limit state, when over the limit.
+//   - ErrCostExceedsLimit when req.Cost is more than the whole limit.
+//
+// When the limiter fails, the request is allowed with a zero LimitResult
+// if cfg.FailOpen is set and refused with ErrLimiterUnavailable otherwise.
+// It is shared by the HTTP middleware and the gRPC interceptors.
+func (cfg *RateLimitConfig) Check(ctx context.Context, req CheckRequest) (LimitResult, APIKey, error) {
+	limiter, identifier := cfg.AnonymousLimiter, req.ClientIP
+	tier := metrics.TierAnonymous
+	var key APIKey
+	if req.APIKey != "" {
+		tier = metrics.TierAPIKey
+		var err error
+		key, err = cfg.APIKeyService.Lookup(req.APIKey)
+		if err == nil && !key.AllowsOrigin(req.Origin) {
+			err = ErrOriginNotAllowed
+		}
+		if err != nil {
+			metrics.RateLimitDecision(tier, keyDecision(err))
+			return LimitResult{}, key, err
 		}
+		limiter, identifier = cfg.keyLimiter(key), key.ID
+	}
 
-		return c.Next()
+	result, err := limiter.CheckN(ctx, identifier, req.Cost)
+	if err != nil {
+		cfg.logUnavailable(ctx, tier, err)
+		if cfg.FailOpen {
+			metrics.RateLimitDecision(tier, metrics.DecisionFailOpen)
+			return LimitResult{Allowed: true}, key, nil
+		}
+		metrics.RateLimitDecision(tier, metrics.DecisionUnavailable)
+		return LimitResult{}, key, ErrLimiterUnavailable
 	}
+	if !result.Allowed && req.Cost > result.Limit {
+		metrics.RateLimitDecision(tier, metrics.DecisionCostExceeded)
+		return result, key, fmt.Errorf("%w: the request costs %d requests, the limit is %d per minute", ErrCostExceedsLimit, req.Cost, result.Limit)
+	}
+	if !result.Allowed {
+		metrics.RateLimitDecision(tier, metrics.DecisionBlocked)
+		return result, key, ErrRateLimitExceeded
+	}
+	metrics.RateLimitDecision(tier, metrics.DecisionAllowed)
+	return result, key, nil
+}
+
+// keyLimiter returns the limiter of key.
+func (cfg *RateLimitConfig) keyLimiter(key APIKey) Limiter {
+	if limiter, ok := cfg.KeyLimiters[key.RateLimit]; ok {
+		return limiter
+	}
+	return cfg.APIKeyLimiter
+}
+
+// keyDecision returns the rate limit decision recorded for a key error.
+func keyDecision(err error) string {
+	switch {
+	case errors.Is(err, ErrAPIKeyExpired):
+		return metrics.DecisionExpiredKey
+	case errors.Is(err, ErrAPIKeyDisabled):
+		return metrics.DecisionDisabledKey
+	case errors.Is(err, ErrOriginNotAllowed):
+		return metrics.DecisionOriginDenied
+	}
+	return metrics.DecisionInvalidKey
+}
+
+// logUnavailable warns that a limiter failed, at most once per
+// unavailableLogInterval.
+func (cfg *RateLimitConfig) logUnavailable(ctx context.Context, tier string, err error) {
+	now := time.Now().UnixNano()
+	last := cfg.lastUnavailableLog.Load()
+	if now-last < int64(unavailableLogInterval) || !cfg.lastUnavailableLog.CompareAndSwap(last, now) {
+		return
+	}
+	slog.WarnContext(ctx, "Rate limiter unavailable", "tier", tier, "fail_open", cfg.FailOpen, "error", err)
+}
+
+// cost returns the weight of the request against the rate limit.
+func (cfg *RateLimitConfig) cost(c *fiber.Ctx) int {
+	if cfg.Cost == nil {
+		return 1
+	}
+	return cfg.Cost(c)
 }
 
 // setRateLimitHeaders writes the standard rate limit headers to the response.
@@ -110,11 +299,22 @@ func setRateLimitHeaders(c *fiber.Ctx, r LimitResult) {
 
 // rateLimitExceededResponse returns the standardised 429 error response.
 func rateLimitExceededResponse(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+	return errorResponse(c, fiber.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED", "Too many requests", ErrRateLimitExceeded)
+}
+
+// errorResponse writes a middleware error. Unversioned and v1 routes keep
+// the original {"success": false, "error": {...}} body; v2 routes use the
+// same problem details as the handlers.
+func errorResponse(c *fiber.Ctx, status int, code, message string, err error) error {
+	if render.VersionOf(c) == render.V2 {
+		return render.Send(c, status, model.NewErrorResponse(status, code, err))
+	}
+	return render.Send(c, status, fiber.Map{
 		"success": false,
 		"error": fiber.Map{
-			"code":    "RATE_LIMIT_EXCEEDED",
-			"message": "Too many requests",
+			"code":       code,
+			"message":    message,
+			"request_id": render.RequestID(c),
 		},
 	})
 }
@@ -1,18 +1,37 @@
 package middleware
 
 import (
+	"context"
 	"sync"
 	"time"
 )
 
+// Limiter enforces a per-identifier request limit over a sliding window.
+// RateLimiter keeps the windows in memory, per process; RedisLimiter
+// shares them between replicas.
+type Limiter interface {
+	// CheckN charges cost requests to identifier and reports whether they
+	// fit in the limit. The request is rejected without recording anything
+	// if the full cost does not fit. A cost above the limit can never fit:
+	// it is rejected with a zero ResetAt rather than a time to retry at. An
+	// error means the limit could not be checked, e.g. because the store is
+	// unreachable.
+	CheckN(ctx context.Context, identifier string, cost int) (LimitResult, error)
+	// Stop releases the limiter's background work.
+	Stop()
+}
+
 // RateLimiter is an in-memory, thread-safe Sliding Window rate limiter.
-// It is designed to be easily replaced by a Redis-backed implementation
-// in the future — just swap out the store behind the same interface.
+// Every process keeps its own windows, so replicas behind a load balancer
+// each allow the full limit; use RedisLimiter to share them.
 type RateLimiter struct {
 	mu      sync.Mutex
 	windows map[string]*windowEntry
 	limit   int           // max requests per window
 	window  time.Duration // window duration
+
+	stop     chan struct{} // closed by Stop to end cleanupLoop
+	stopOnce sync.Once
 }
 
 // windowEntry holds the sliding window state for one identifier.
@@ -34,10 +53,11 @@ func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
 		windows: make(map[string]*windowEntry),
 		limit:   limit,
 		window:  window,
+		stop:    make(chan struct{}),
 	}
 
 	// Background goroutine to periodically evict idle entries and prevent
-	// unbounded memory growth.
+	// unbounded memory growth. It runs until Stop is called.
 	go rl.cleanupLoop()
 
 	return rl
@@ -47,6 +67,26 @@ func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
 // It records the current request timestamp and returns a LimitResult.
 // Thread-safe.
 func (rl *RateLimiter) Check(identifier string) LimitResult {
+	return rl.check(identifier, 1)
+}
+
+// CheckN is like Check but charges cost requests at once, for operations
+// such as batch lookups that do the work of many single requests.
+// The request is rejected without recording anything if the full cost
+// does not fit in the remaining window. It never fails.
+func (rl *RateLimiter) CheckN(_ context.Context, identifier string, cost int) (LimitResult, error) {
+	return rl.check(identifier, cost), nil
+}
+
+// check implements Check and CheckN.
+func (rl *RateLimiter) check(identifier string, cost int) LimitResult {
+	if cost < 1 {
+		cost = 1
+	}
+	if cost > rl.limit {
+		return LimitResult{Limit: rl.limit}
+	}
+
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -64,19 +104,24 @@ func (rl *RateLimiter) Check(identifier string) LimitResult {
 
 	count := len(entry.timestamps)
 
-	if count >= rl.limit {
+	if count+cost > rl.limit {
 		// Limit exceeded — compute when the oldest request will expire.
-		resetAt := entry.timestamps[0].Add(rl.window)
+		resetAt := now.Add(rl.window)
+		if count > 0 {
+			resetAt = entry.timestamps[0].Add(rl.window)
+		}
 		return LimitResult{
 			Allowed:   false,
 			Limit:     rl.limit,
-			Remaining: 0,
+			Remaining: rl.limit - count,
 			ResetAt:   resetAt,
 		}
 	}
 
-	// Within limit — record this request.
-	entry.timestamps = append(entry.timestamps, now)
+	// Within limit — record this request, once per unit of cost.
+	for i := 0; i < cost; i++ {
+		entry.timestamps = append(entry.timestamps, now)
+	}
 	remaining := rl.limit - len(entry.timestamps)
 
 	// ResetAt: when the oldest request in the window will expire.
@@ -93,6 +138,20 @@ func (rl *RateLimiter) Check(identifier string) LimitResult {
 	}
 }
 
+// Stop ends the background cleanup. The limiter keeps answering checks
+// afterwards, but idle entries are no longer evicted. It is safe to call
+// more than once.
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() { close(rl.stop) })
+}
+
+// Len returns the number of identifiers with an active window.
+func (rl *RateLimiter) Len() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.windows)
+}
+
 // filterAfter returns only timestamps strictly after the cutoff (in-place reuse of slice).
 func filterAfter(ts []time.Time, cutoff time.Time) []time.Time {
 	i := 0
@@ -106,11 +165,16 @@ func filterAfter(ts []time.Time, cutoff time.Time) []time.Time {
 }
 
 // cleanupLoop removes identifiers whose windows have gone completely idle.
-// Runs every minute to keep memory usage bounded.
+// Runs every minute to keep memory usage bounded, until Stop is called.
 func (rl *RateLimiter) cleanupLoop() {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
-	for range ticker.C {
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case <-ticker.C:
+		}
 		rl.mu.Lock()
 		cutoff := time.Now().Add(-rl.window)
 		for id, entry := range rl.windows {
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// limiterAlgorithms are the in-memory limiters, by the name RATE_LIMIT_ALGORITHM
+// selects them with.
+var limiterAlgorithms = []struct {
+	name string
+	new  func(limit int) Limiter
+}{
+	{"sliding_log", func(limit int) Limiter { return NewRateLimiter(limit, RateLimitWindow) }},
+	{"sliding_window", func(limit int) Limiter { return NewSlidingWindowLimiter(limit, RateLimitWindow) }},
+	{"gcra", func(limit int) Limiter { return NewGCRALimiter(limit, RateLimitWindow) }},
+}
+
+// clientIPs returns n distinct client IPs.
+func clientIPs(n int) []string {
+	ips := make([]string, n)
+	for i := range ips {
+		ips[i] = "10." + strconv.Itoa(i>>16&255) + "." + strconv.Itoa(i>>8&255) + "." + strconv.Itoa(i&255)
+	}
+	return ips
+}
+
+// BenchmarkLimiterCheck measures concurrent checks of each algorithm when
+// every goroutine hits one API key at the API key tier limit, and when
+// they spread over 10 000 client IPs at the anonymous limit. Run it with
+// -cpu to vary the number of goroutines:
+//
+//	go test -run '^$' -bench LimiterCheck -cpu 1,8,32 ./internal/middleware
+func BenchmarkLimiterCheck(b *testing.B) {
+	ips := clientIPs(10000)
+	for _, alg := range limiterAlgorithms {
+		b.Run(alg.name+"/hot_key", func(b *testing.B) {
+			benchmarkChecks(b, alg.new(DefaultLimitAPIKey), []string{"api-key"})
+		})
+		b.Run(alg.name+"/many_keys", func(b *testing.B) {
+			benchmarkChecks(b, alg.new(DefaultLimitAnonymous), ips)
+		})
+	}
+}
+
+// benchmarkChecks runs checks of l in parallel, each goroutine cycling
+// through ids from its own offset.
+func benchmarkChecks(b *testing.B, l Limiter, ids []string) {
+	defer l.Stop()
+	var offset atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		ctx := context.Background()
+		i := int(offset.Add(7919))
+		for pb.Next() {
+			l.CheckN(ctx, ids[i%len(ids)], 1)
+			i++
+		}
+	})
+}
+
+// BenchmarkLimiterMemory measures the heap each algorithm holds per
+// identifier once it has used its full limit at the API key tier, reported
+// as B/identifier.
+func BenchmarkLimiterMemory(b *testing.B) {
+	for _, alg := range limiterAlgorithms {
+		b.Run(alg.name, func(b *testing.B) {
+			ids := clientIPs(b.N)
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+
+			l := alg.new(DefaultLimitAPIKey)
+			defer l.Stop()
+			ctx := context.Background()
+			b.ResetTimer()
+			for _, id := range ids {
+				for range DefaultLimitAPIKey {
+					l.CheckN(ctx, id, 1)
+				}
+			}
+			b.StopTimer()
+
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			runtime.KeepAlive(l)
+			b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc))/float64(b.N), "B/identifier")
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+)
+
+// readyRetryAfter is the Retry-After value, in seconds, sent while not ready.
+const readyRetryAfter = "5"
+
+// ErrNotReady is reported for requests received before the server is ready.
+var ErrNotReady = errors.New("service is starting up")
+
+// RequireReady returns a Fiber handler that answers 503 Service Unavailable
+// with a Retry-After header until ready reports true, so no request reaches
+// a handler before the dataset is loaded.
+func RequireReady(ready func() bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ready() {
+			return c.Next()
+		}
+		c.Set(fiber.HeaderRetryAfter, readyRetryAfter)
+		return render.Send(c, fiber.StatusServiceUnavailable, model.NewErrorResponse(
+			fiber.StatusServiceUnavailable,
+			"SERVICE_UNAVAILABLE",
+			ErrNotReady,
+		))
+	}
+}
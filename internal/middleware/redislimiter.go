@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript charges a cost to a sliding window kept in a sorted
+// set of request timestamps, atomically, so that every replica sharing the
+// Redis server enforces one limit. It mirrors RateLimiter: timestamps at or
+// before now-window are evicted, nothing is recorded when the cost does not
+// fit, and the window resets when its oldest request expires.
+//
+// KEYS[1] is the window key. ARGV holds the current time and the window in
+// milliseconds, the limit, the cost and a prefix unique to the request for
+// the sorted set members. It returns {allowed (0 or 1), count, reset in
+// milliseconds}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count + cost <= limit then
+	for i = 1, cost do
+		redis.call('ZADD', key, now, ARGV[5] .. i)
+	end
+	count = count + cost
+	allowed = 1
+end
+
+local reset = now + window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window
+end
+if count > 0 then
+	redis.call('PEXPIRE', key, window)
+end
+return {allowed, count, reset}
+`)
+
+// RedisLimiter is a sliding window rate limiter whose windows live in Redis,
+// so replicas sharing the server enforce one limit between them. Each check
+// runs one atomic Lua script. Timestamps come from the replicas' clocks,
+// which must be kept in sync.
+type RedisLimiter struct {
+	client redis.Scripter
+	prefix string // key prefix, e.g. "geo-id:ratelimit:anonymous:"
+	limit  int
+	window time.Duration
+
+	// instance and seq make sorted set members unique across replicas and
+	// requests charged in the same millisecond.
+	instance string
+	seq      atomic.Uint64
+}
+
+// NewRedisLimiter creates a RedisLimiter with the given limit and window,
+// storing windows under keys starting with prefix. client is owned by the
+// caller, who closes it after Stop.
+func NewRedisLimiter(client redis.Scripter, prefix string, limit int, window time.Duration) *RedisLimiter {
+	var id [6]byte
+	rand.Read(id[:])
+	return &RedisLimiter{
+		client:   client,
+		prefix:   prefix,
+		limit:    limit,
+		window:   window,
+		instance: hex.EncodeToString(id[:]),
+	}
+}
+
+// CheckN charges cost requests to identifier. Identifiers are hashed into
+// the key so that API keys are not stored in Redis.
+func (rl *RedisLimiter) CheckN(ctx context.Context, identifier string, cost int) (LimitResult, error) {
+	if cost < 1 {
+		cost = 1
+	}
+	if cost > rl.limit {
+		return LimitResult{Limit: rl.limit}, nil
+	}
+	sum := sha256.Sum256([]byte(identifier))
+	key := rl.prefix + hex.EncodeToString(sum[:])
+	member := rl.instance + ":" + strconv.FormatUint(rl.seq.Add(1), 36) + ":"
+
+	now := time.Now().UnixMilli()
+	reply, err := slidingWindowScript.Run(ctx, rl.client, []string{key},
+		now, rl.window.Milliseconds(), rl.limit, cost, member).Int64Slice()
+	if err != nil {
+		return LimitResult{}, fmt.Errorf("redis rate limiter: %w", err)
+	}
+	if len(reply) != 3 {
+		return LimitResult{}, fmt.Errorf("redis rate limiter: unexpected reply %v", reply)
+	}
+	return LimitResult{
+		Allowed:   reply[0] == 1,
+		Limit:     rl.limit,
+		Remaining: rl.limit - int(reply[1]),
+		ResetAt:   time.UnixMilli(reply[2]),
+	}, nil
+}
+
+// Stop does nothing: the windows expire in Redis on their own.
+func (rl *RedisLimiter) Stop() {}
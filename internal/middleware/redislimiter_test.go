@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const testPrefix = "geo-id:ratelimit:test:"
+
+// newTestRedis starts a miniredis server and returns it with a client that
+// fails at once, without retries, when the server is gone.
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return mr, client
+}
+
+func checkN(t *testing.T, l Limiter, identifier string, cost int) LimitResult {
+	t.Helper()
+	result, err := l.CheckN(context.Background(), identifier, cost)
+	if err != nil {
+		t.Fatalf("CheckN(%q, %d): %v", identifier, cost, err)
+	}
+	return result
+}
+
+func TestRedisLimiterWindowExpires(t *testing.T) {
+	_, client := newTestRedis(t)
+	const window = 200 * time.Millisecond
+	l := NewRedisLimiter(client, testPrefix, 3, window)
+
+	for i := range 3 {
+		if r := checkN(t, l, "10.0.0.1", 1); !r.Allowed || r.Remaining != 2-i {
+			t.Fatalf("request %d: got allowed %v, remaining %d", i+1, r.Allowed, r.Remaining)
+		}
+	}
+	if r := checkN(t, l, "10.0.0.1", 1); r.Allowed {
+		t.Fatal("request over the limit was allowed")
+	}
+	if r := checkN(t, l, "10.0.0.2", 1); !r.Allowed {
+		t.Fatal("another identifier shares the window")
+	}
+
+	time.Sleep(window + 50*time.Millisecond)
+	if r := checkN(t, l, "10.0.0.1", 1); !r.Allowed || r.Remaining != 2 {
+		t.Fatalf("after the window: got allowed %v, remaining %d, want the window reset", r.Allowed, r.Remaining)
+	}
+}
+
+func TestRedisLimiterWeightedCost(t *testing.T) {
+	_, client := newTestRedis(t)
+	l := NewRedisLimiter(client, testPrefix, 10, time.Minute)
+
+	tests := []struct {
+		cost          int
+		wantAllowed   bool
+		wantRemaining int
+	}{
+		{cost: 4, wantAllowed: true, wantRemaining: 6},
+		{cost: 7, wantAllowed: false, wantRemaining: 6}, // rejected without being charged
+		{cost: 0, wantAllowed: true, wantRemaining: 5},  // counts as 1
+		{cost: 5, wantAllowed: true, wantRemaining: 0},
+		{cost: 1, wantAllowed: false, wantRemaining: 0},
+	}
+	for _, tt := range tests {
+		r := checkN(t, l, "api-key", tt.cost)
+		if r.Allowed != tt.wantAllowed || r.Remaining != tt.wantRemaining || r.Limit != 10 {
+			t.Fatalf("cost %d: got allowed %v, remaining %d, limit %d; want %v, %d, 10",
+				tt.cost, r.Allowed, r.Remaining, r.Limit, tt.wantAllowed, tt.wantRemaining)
+		}
+	}
+
+	r := checkN(t, l, "other-key", 11)
+	if r.Allowed || !r.ResetAt.IsZero() {
+		t.Fatalf("cost above the limit: got allowed %v, reset %v; want rejected with a zero reset", r.Allowed, r.ResetAt)
+	}
+}
+
+func TestRedisLimiterResetAt(t *testing.T) {
+	_, client := newTestRedis(t)
+	const window = time.Minute
+	l := NewRedisLimiter(client, testPrefix, 2, window)
+
+	first := time.Now()
+	r := checkN(t, l, "10.0.0.1", 1)
+	assertNear(t, "first request", r.ResetAt, first.Add(window))
+
+	time.Sleep(20 * time.Millisecond)
+	checkN(t, l, "10.0.0.1", 1)
+	r = checkN(t, l, "10.0.0.1", 1)
+	if r.Allowed {
+		t.Fatal("request over the limit was allowed")
+	}
+	// The window resets when its oldest request expires.
+	assertNear(t, "rejected request", r.ResetAt, first.Add(window))
+}
+
+func assertNear(t *testing.T, name string, got, want time.Time) {
+	t.Helper()
+	if d := got.Sub(want); d < -10*time.Millisecond || d > 10*time.Millisecond {
+		t.Fatalf("%s: reset at %v, want about %v", name, got, want)
+	}
+}
+
+func TestRedisLimiterHashesIdentifiers(t *testing.T) {
+	mr, client := newTestRedis(t)
+	l := NewRedisLimiter(client, testPrefix, 10, time.Minute)
+
+	const key = "secret-api-key"
+	checkN(t, l, key, 1)
+
+	sum := sha256.Sum256([]byte(key))
+	want := testPrefix + hex.EncodeToString(sum[:])
+	keys := mr.Keys()
+	if len(keys) != 1 || keys[0] != want {
+		t.Fatalf("got keys %q, want [%q]", keys, want)
+	}
+	if strings.Contains(keys[0], key) {
+		t.Fatalf("key %q holds the identifier", keys[0])
+	}
+	if ttl := mr.TTL(want); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("got TTL %v, want the window", ttl)
+	}
+}
+
+func TestRateLimitMiddlewareRedisUnavailable(t *testing.T) {
+	tests := []struct {
+		name       string
+		failOpen   bool
+		wantStatus int
+	}{
+		{name: "fail closed", failOpen: false, wantStatus: fiber.StatusServiceUnavailable},
+		{name: "fail open", failOpen: true, wantStatus: fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, client := newTestRedis(t)
+			keys, err := NewAPIKeyService(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := &RateLimitConfig{
+				APIKeyService:    keys,
+				AnonymousLimiter: NewRedisLimiter(client, testPrefix+"anonymous:", 60, time.Minute),
+				APIKeyLimiter:    NewRedisLimiter(client, testPrefix+"api_key:", 1000, time.Minute),
+				FailOpen:         tt.failOpen,
+			}
+			app := fiber.New()
+			app.Use(RateLimitMiddleware(cfg))
+			app.Get("/v1/states", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+			mr.Close()
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/v1/states", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.failOpen {
+				if limit := resp.Header.Get("X-RateLimit-Limit"); limit != "" {
+					t.Fatalf("got X-RateLimit-Limit %q, want none", limit)
+				}
+			} else if retry := resp.Header.Get(fiber.HeaderRetryAfter); retry != unavailableRetryAfter {
+				t.Fatalf("got Retry-After %q, want %q", retry, unavailableRetryAfter)
+			}
+
+			result, _, err := cfg.Check(context.Background(), CheckRequest{ClientIP: "10.0.0.1", Cost: 1})
+			switch {
+			case tt.failOpen && (err != nil || !result.Allowed || result.Limit != 0):
+				t.Fatalf("Check: got %+v, %v; want allowed with limit 0", result, err)
+			case !tt.failOpen && !errors.Is(err, ErrLimiterUnavailable):
+				t.Fatalf("Check: got error %v, want %v", err, ErrLimiterUnavailable)
+			}
+		})
+	}
+}
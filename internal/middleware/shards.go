@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// limiterShards is the number of independently locked maps of the sharded
+// limiters, so that checks for different identifiers rarely wait on the
+// same lock.
+const limiterShards = 64
+
+// shardedState holds limiter state of type S per identifier, spread over
+// limiterShards maps by a hash of the identifier. Idle entries are evicted
+// every minute until Stop is called.
+type shardedState[S any] struct {
+	shards [limiterShards]stateShard[S]
+	// idle reports whether an entry can be evicted at now, Unix nanoseconds.
+	idle func(s S, now int64) bool
+
+	stop     chan struct{} // closed by Stop to end cleanupLoop
+	stopOnce sync.Once
+}
+
+type stateShard[S any] struct {
+	mu      sync.Mutex
+	entries map[string]S
+	// Padding keeps neighbouring locks on separate cache lines.
+	_ [48]byte
+}
+
+// newShardedState creates an empty shardedState and starts its cleanup.
+func newShardedState[S any](idle func(s S, now int64) bool) *shardedState[S] {
+	st := &shardedState[S]{idle: idle, stop: make(chan struct{})}
+	for i := range st.shards {
+		st.shards[i].entries = make(map[string]S)
+	}
+	go st.cleanupLoop()
+	return st
+}
+
+// lock locks and returns the shard of identifier. The caller reads and
+// writes the identifier's entry, the zero S when there is none, and then
+// unlocks the shard.
+func (st *shardedState[S]) lock(identifier string) *stateShard[S] {
+	sh := &st.shards[shardOf(identifier)]
+	sh.mu.Lock()
+	return sh
+}
+
+// Len returns the number of identifiers with state.
+func (st *shardedState[S]) Len() int {
+	n := 0
+	for i := range st.shards {
+		sh := &st.shards[i]
+		sh.mu.Lock()
+		n += len(sh.entries)
+		sh.mu.Unlock()
+	}
+	return n
+}
+
+// Stop ends the background cleanup. It is safe to call more than once.
+func (st *shardedState[S]) Stop() {
+	st.stopOnce.Do(func() { close(st.stop) })
+}
+
+// cleanupLoop evicts idle entries every minute, one shard at a time, until
+// Stop is called.
+func (st *shardedState[S]) cleanupLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-st.stop:
+			return
+		case <-ticker.C:
+		}
+		for i := range st.shards {
+			sh := &st.shards[i]
+			sh.mu.Lock()
+			now := time.Now().UnixNano()
+			for id, s := range sh.entries {
+				if st.idle(s, now) {
+					delete(sh.entries, id)
+				}
+			}
+			sh.mu.Unlock()
+		}
+	}
+}
+
+// shardOf returns the shard of identifier, by its 32-bit FNV-1a hash.
+func shardOf(identifier string) int {
+	h := uint32(2166136261)
+	for i := 0; i < len(identifier); i++ {
+		h ^= uint32(identifier[i])
+		h *= 16777619
+	}
+	return int(h % limiterShards)
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SlidingWindowLimiter is an in-memory rate limiter using a sliding window
+// counter: it counts requests in fixed windows and estimates the sliding
+// window ending now as the current count plus the previous count weighted
+// by how much of the previous window the sliding one still covers. It
+// keeps two counters per identifier, checks in constant time and spreads
+// identifiers over sharded locks.
+//
+// The estimate assumes requests were spread evenly over the previous
+// window, so it can be off by a fraction of the limit when they were not;
+// it is rounded up, erring towards rejecting.
+type SlidingWindowLimiter struct {
+	state  *shardedState[windowCounts]
+	limit  int
+	window time.Duration
+}
+
+// windowCounts is the state of one identifier.
+type windowCounts struct {
+	start      int64 // start of the current fixed window, Unix nanoseconds
+	prev, curr int   // requests in the previous and current fixed window
+}
+
+// NewSlidingWindowLimiter creates a SlidingWindowLimiter admitting limit
+// requests per window. It panics unless limit is at least 1 and window is
+// positive.
+func NewSlidingWindowLimiter(limit int, window time.Duration) *SlidingWindowLimiter {
+	if limit < 1 || window <= 0 {
+		panic(fmt.Sprintf("middleware: NewSlidingWindowLimiter needs a limit of at least 1 and a positive window, got %d per %v", limit, window))
+	}
+	w := int64(window)
+	return &SlidingWindowLimiter{
+		state:  newShardedState(func(s windowCounts, now int64) bool { return now >= s.start+2*w }),
+		limit:  limit,
+		window: window,
+	}
+}
+
+// CheckN charges cost requests to identifier. ResetAt is when the full
+// limit is available again for admitted requests, and when the request
+// would be admitted for rejected ones; it is zero when cost is above the
+// limit and the request could never be admitted. It never fails.
+func (l *SlidingWindowLimiter) CheckN(_ context.Context, identifier string, cost int) (LimitResult, error) {
+	if cost < 1 {
+		cost = 1
+	}
+	if cost > l.limit {
+		return LimitResult{Limit: l.limit}, nil
+	}
+	sh := l.state.lock(identifier)
+	defer sh.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	w := int64(l.window)
+	start := now - now%w
+	s := sh.entries[identifier]
+	switch s.start {
+	case start:
+	case start - w:
+		s.prev, s.curr = s.curr, 0
+	default:
+		s.prev, s.curr = 0, 0
+	}
+	s.start = start
+
+	// The previous window's share, rounded up.
+	left := w - (now - start)
+	used := int((int64(s.prev)*left+w-1)/w) + s.curr
+	if used+cost > l.limit {
+		sh.entries[identifier] = s
+		return LimitResult{
+			Allowed:   false,
+			Limit:     l.limit,
+			Remaining: max(l.limit-used, 0),
+			ResetAt:   time.Unix(0, l.fitsAt(s, cost)),
+		}, nil
+	}
+	s.curr += cost
+	sh.entries[identifier] = s
+	return LimitResult{
+		Allowed:   true,
+		Limit:     l.limit,
+		Remaining: l.limit - used - cost,
+		ResetAt:   time.Unix(0, start+2*w),
+	}, nil
+}
+
+// fitsAt returns when cost more requests fit in the limit, in Unix
+// nanoseconds, as the weight of the earlier windows decreases.
+func (l *SlidingWindowLimiter) fitsAt(s windowCounts, cost int) int64 {
+	w := int64(l.window)
+	if free := int64(l.limit - s.curr - cost); free >= 0 && s.prev > 0 {
+		// Later in the current window: prev*(w-elapsed)/w <= free.
+		return s.start + w - free*w/int64(s.prev)
+	}
+	if free := int64(l.limit - cost); free >= 0 && s.curr > 0 {
+		// In the next window, where the current one is the previous.
+		return max(s.start+2*w-free*w/int64(s.curr), s.start+w)
+	}
+	return s.start + 2*w
+}
+
+// Len returns the number of identifiers with requests in the last two
+// fixed windows, up to a minute after.
+func (l *SlidingWindowLimiter) Len() int {
+	return l.state.Len()
+}
+
+// Stop ends the background cleanup. It is safe to call more than once.
+func (l *SlidingWindowLimiter) Stop() {
+	l.state.Stop()
+}
@@ -0,0 +1,26 @@
+package model
+
+// BatchRequest is the body of a batch region lookup
+// @Description Batch region lookup request
+type BatchRequest struct {
+	Codes            []string `json:"codes" example:"11,11.01,11.01.01.2001"`
+	IncludeAncestors bool     `json:"include_ancestors" example:"false"`
+}
+
+// BatchItem is the lookup result for one code of a batch request.
+// Exactly one of Region or Error is set.
+// @Description Result for a single code in a batch lookup
+type BatchItem struct {
+	Code      string      `json:"code" example:"11.01"`
+	Level     string      `json:"level,omitempty" example:"city"`
+	Region    *Region     `json:"region,omitempty"`
+	Ancestors []Region    `json:"ancestors,omitempty"`
+	Error     *BatchError `json:"error,omitempty"`
+}
+
+// BatchError describes why a single code in a batch could not be resolved
+// @Description Per-item batch lookup error
+type BatchError struct {
+	Code    string `json:"code" example:"NOT_FOUND"`
+	Message string `json:"message" example:"city not found"`
+}
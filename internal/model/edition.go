@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// Edition describes the release of the administrative dataset being served
+// @Description Dataset edition metadata
+type Edition struct {
+	Name      string    `json:"name" example:"Kepmendagri No 300.2.2-2138 Tahun 2025"`
+	Source    string    `json:"source" example:"https://raw.githubusercontent.com/cahyadsn/wilayah/master/db/wilayah.sql"`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-10-01T08:45:08+07:00"`
+}
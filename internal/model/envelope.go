@@ -0,0 +1,43 @@
+package model
+
+import "net/http"
+
+// Envelope represents a successful API v2 response
+// @Description API v2 response wrapper
+type Envelope struct {
+	Data       interface{} `json:"data"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Problem represents an API v2 error as RFC 7807 problem details, served as
+// application/problem+json
+// @Description API v2 error (RFC 7807 problem details)
+type Problem struct {
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail,omitempty" example:"city not found"`
+	Instance string `json:"instance,omitempty" example:"/v2/cities/99.99"`
+	Code     string `json:"code" example:"NOT_FOUND"`
+	// RequestID echoes the X-Request-ID response header (an extension member)
+	RequestID string `json:"request_id,omitempty" example:"0b6d4f1e-8c1f-4a57-9d55-1f3b0c8e2a41"`
+}
+
+// NewEnvelope converts a v1 success response to the v2 envelope
+func NewEnvelope(resp APIResponse) Envelope {
+	return Envelope{Data: resp.Data, Pagination: resp.Pagination}
+}
+
+// NewProblem converts a v1 error response to problem details for the
+// request path instance
+func NewProblem(resp APIErrorResponse, instance string) Problem {
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(resp.Status),
+		Status:    resp.Status,
+		Detail:    resp.Error,
+		Instance:  instance,
+		Code:      resp.Message,
+		RequestID: resp.RequestID,
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// HealthStatus is the body of the actuator health endpoints
+// @Description Liveness or readiness report
+type HealthStatus struct {
+	Status         string        `json:"status" example:"UP"`
+	Edition        *Edition      `json:"edition,omitempty"`
+	Counts         *RegionCounts `json:"counts,omitempty"`
+	LoadedAt       *time.Time    `json:"loaded_at,omitempty" example:"2026-01-01T00:00:00Z"`
+	LoadDurationMS int64         `json:"load_duration_ms,omitempty" example:"1250"`
+	UptimeSeconds  int64         `json:"uptime_seconds" example:"3600"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// RegionCounts is the number of regions loaded per level
+// @Description Region counts per level
+type RegionCounts struct {
+	States    int `json:"states" example:"38"`
+	Cities    int `json:"cities" example:"514"`
+	Districts int `json:"districts" example:"7265"`
+	Villages  int `json:"villages" example:"83345"`
+}
@@ -0,0 +1,20 @@
+package model
+
+// NIKDecoding is a NIK decoded into its region of registration, birth date,
+// gender and serial
+// @Description Decoded NIK (national identity number)
+type NIKDecoding struct {
+	NIK string `json:"nik" example:"3273014101900001"`
+	// State, City and District are null when the prefix does not resolve
+	State    *Region `json:"state"`
+	City     *Region `json:"city"`
+	District *Region `json:"district"`
+	// RegionStatus is current when the district exists in the current
+	// edition, past when it only exists in a past edition and unknown
+	// otherwise
+	RegionStatus string `json:"region_status" example:"current" enums:"current,past,unknown"`
+	Edition      string `json:"edition,omitempty" example:"Kepmendagri No 300.2.2-2138 Tahun 2025"`
+	BirthDate    string `json:"birth_date" example:"1990-01-01"`
+	Gender       string `json:"gender" example:"female" enums:"male,female"`
+	Serial       string `json:"serial" example:"0001"`
+}
@@ -4,6 +4,28 @@ package model
 // @Description Region information
 // @name Region
 type Region struct {
-	Code  string `json:"code" example:"11"`
-	Value string `json:"value" example:"ACEH"`
+	Code     string   `json:"code" example:"11"`
+	Value    string   `json:"value" example:"ACEH"`
+	Children []Region `json:"children,omitempty"`
+}
+
+// RegionDetail is a region of any level together with links to its parent
+// and children
+// @Description Region with its level and related links
+// @name RegionDetail
+type RegionDetail struct {
+	Code       string      `json:"code" example:"11.01"`
+	Value      string      `json:"value" example:"KAB. ACEH SELATAN"`
+	Level      string      `json:"level" example:"city"`
+	Type       string      `json:"type" example:"kabupaten"`
+	ParentCode string      `json:"parent_code,omitempty" example:"11"`
+	Links      RegionLinks `json:"links"`
+}
+
+// RegionLinks holds the paths of a region and its related resources.
+// Parent is empty for states and Children is empty for villages.
+type RegionLinks struct {
+	Self     string `json:"self" example:"/regions/11.01"`
+	Parent   string `json:"parent,omitempty" example:"/regions/11"`
+	Children string `json:"children,omitempty" example:"/regions/11.01/children"`
 }
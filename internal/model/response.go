@@ -3,17 +3,28 @@ package model
 // APIResponse represents a successful API response
 // @Description Successful API response wrapper
 type APIResponse struct {
-	Status  int         `json:"status" example:"200"`
-	Message string      `json:"message" example:"SUCCESS"`
-	Data    interface{} `json:"data"`
+	Status     int         `json:"status" example:"200"`
+	Message    string      `json:"message" example:"SUCCESS"`
+	Data       interface{} `json:"data"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes the page of a list returned in an APIResponse
+// @Description Pagination metadata for list responses
+type Pagination struct {
+	Total      int    `json:"total" example:"23"`
+	Limit      int    `json:"limit" example:"10"`
+	Offset     int    `json:"offset" example:"0"`
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJvIjoxMCwiZiI6ImNvZGV8In0"`
 }
 
 // APIErrorResponse represents an error API response
 // @Description Error API response wrapper
 type APIErrorResponse struct {
-	Status  int    `json:"status" example:"404"`
-	Message string `json:"message" example:"NOT_FOUND"`
-	Error   string `json:"error" example:"Region not found"`
+	Status    int    `json:"status" example:"404"`
+	Message   string `json:"message" example:"NOT_FOUND"`
+	Error     string `json:"error" example:"Region not found"`
+	RequestID string `json:"request_id,omitempty" example:"0b6d4f1e-8c1f-4a57-9d55-1f3b0c8e2a41"`
 }
 
 // NewSuccessResponse creates a new success response
@@ -25,6 +36,13 @@ func NewSuccessResponse(data interface{}) APIResponse {
 	}
 }
 
+// NewPaginatedResponse creates a new success response carrying pagination metadata
+func NewPaginatedResponse(data interface{}, pagination Pagination) APIResponse {
+	resp := NewSuccessResponse(data)
+	resp.Pagination = &pagination
+	return resp
+}
+
 // NewErrorResponse creates a new error response
 func NewErrorResponse(status int, message string, err error) APIErrorResponse {
 	return APIErrorResponse{
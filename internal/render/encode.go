@@ -0,0 +1,323 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// field is one member of a JSON object.
+type field struct {
+	key   string
+	value interface{}
+}
+
+// object is a JSON object that keeps its members in document order, so the
+// derived formats list fields in the same order as the JSON envelope.
+type object []field
+
+func (o object) get(key string) (interface{}, bool) {
+	for _, f := range o {
+		if f.key == key {
+			return f.value, true
+		}
+	}
+	return nil, false
+}
+
+// decodeOrdered parses JSON into object, []interface{}, string,
+// json.Number, bool or nil values.
+func decodeOrdered(raw []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return decodeValue(dec)
+}
+
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, field{key: key.(string), value: value})
+		}
+		_, err = dec.Token()
+		return obj, err
+	default:
+		arr := []interface{}{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err = dec.Token()
+		return arr, err
+	}
+}
+
+// writeXML renders the envelope under a <response> root, or a <problem>
+// root for problem details. Object members become elements named after
+// their keys and array entries become <item>.
+func writeXML(w io.Writer, tree interface{}, problem bool) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	root := xml.StartElement{Name: xml.Name{Local: "response"}}
+	if problem {
+		// RFC 7807 appendix A: problem details in the urn:ietf:rfc:7807 namespace.
+		root = xml.StartElement{
+			Name: xml.Name{Local: "problem"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: "urn:ietf:rfc:7807"}},
+		}
+	}
+	enc := xml.NewEncoder(w)
+	if err := encodeXMLElement(enc, root, tree); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func encodeXML(enc *xml.Encoder, name string, v interface{}) error {
+	return encodeXMLElement(enc, xml.StartElement{Name: xml.Name{Local: name}}, v)
+}
+
+func encodeXMLElement(enc *xml.Encoder, start xml.StartElement, v interface{}) error {
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case object:
+		for _, f := range t {
+			if err := encodeXML(enc, f.key, f.value); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range t {
+			if err := encodeXML(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(scalarString(t))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// writeMsgPack renders the envelope as a MessagePack map mirroring the JSON.
+func writeMsgPack(w io.Writer, tree interface{}) error {
+	return encodeMsgPack(msgpack.NewEncoder(w), tree)
+}
+
+func encodeMsgPack(enc *msgpack.Encoder, v interface{}) error {
+	switch t := v.(type) {
+	case object:
+		if err := enc.EncodeMapLen(len(t)); err != nil {
+			return err
+		}
+		for _, f := range t {
+			if err := enc.EncodeString(f.key); err != nil {
+				return err
+			}
+			if err := encodeMsgPack(enc, f.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		if err := enc.EncodeArrayLen(len(t)); err != nil {
+			return err
+		}
+		for _, item := range t {
+			if err := encodeMsgPack(enc, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return enc.EncodeInt(n)
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return err
+		}
+		return enc.EncodeFloat64(f)
+	default:
+		return enc.Encode(t)
+	}
+}
+
+// writeCSV renders the envelope as a table. When the envelope has a data
+// member, its items are the rows; otherwise (error envelopes) the envelope
+// itself is the single row. Nested objects are flattened into dotted column
+// names and arrays are embedded as JSON. Pagination metadata, which has no
+// place in the table, is sent as X-Pagination-* headers.
+func writeCSV(c *fiber.Ctx, w io.Writer, tree interface{}) error {
+	root, _ := tree.(object)
+
+	rows := []interface{}{root}
+	if data, ok := root.get("data"); ok {
+		if list, isList := data.([]interface{}); isList {
+			rows = list
+		} else {
+			rows = []interface{}{data}
+		}
+	}
+	if pagination, ok := root.get("pagination"); ok {
+		if p, isObj := pagination.(object); isObj {
+			for _, f := range p {
+				c.Set("X-Pagination-"+headerCase(f.key), scalarString(f.value))
+			}
+		}
+	}
+
+	var columns []string
+	seen := map[string]bool{}
+	flat := make([]map[string]string, len(rows))
+	for i, row := range rows {
+		flat[i] = map[string]string{}
+		if obj, isObj := row.(object); isObj {
+			flatten(obj, "", flat[i], func(col string) {
+				if !seen[col] {
+					seen[col] = true
+					columns = append(columns, col)
+				}
+			})
+		} else {
+			flat[i]["value"] = cellString(row)
+			if !seen["value"] {
+				seen["value"] = true
+				columns = append(columns, "value")
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range flat {
+		for i, col := range columns {
+			record[i] = row[col]
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func flatten(obj object, prefix string, out map[string]string, addColumn func(string)) {
+	for _, f := range obj {
+		col := prefix + f.key
+		if nested, ok := f.value.(object); ok {
+			flatten(nested, col+".", out, addColumn)
+			continue
+		}
+		addColumn(col)
+		out[col] = cellString(f.value)
+	}
+}
+
+// cellString renders a value for a CSV cell, embedding arrays and objects as JSON.
+func cellString(v interface{}) string {
+	switch v.(type) {
+	case object, []interface{}:
+		raw, err := json.Marshal(toPlain(v))
+		if err != nil {
+			return ""
+		}
+		return string(raw)
+	default:
+		return scalarString(v)
+	}
+}
+
+// toPlain converts ordered values back into values encoding/json can
+// marshal. Member order is preserved by emitting objects as raw JSON.
+func toPlain(v interface{}) interface{} {
+	switch t := v.(type) {
+	case object:
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for i, f := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(f.key)
+			value, _ := json.Marshal(toPlain(f.value))
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+		return json.RawMessage(buf.Bytes())
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = toPlain(item)
+		}
+		return out
+	default:
+		return t
+	}
+}
+
+func scalarString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		if t {
+			return "true"
+		}
+		return "false"
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// headerCase turns a snake_case key into a Header-Case header suffix.
+func headerCase(key string) string {
+	parts := strings.Split(key, "_")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "-")
+}
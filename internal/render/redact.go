@@ -0,0 +1,40 @@
+package render
+
+import "strings"
+
+// nikPrefix is the part of a NIK kept by RedactPath: its region prefix,
+// which identifies a district rather than a person.
+const nikPrefix = 6
+
+// RedactPath masks the personal part of NIKs in a request path, for logs
+// and traces: a segment of 16 digits keeps its first six digits and the
+// rest is replaced by asterisks.
+func RedactPath(path string) string {
+	if len(path) < 16 {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	redacted := false
+	for i, seg := range segments {
+		if isNIK(seg) {
+			segments[i] = seg[:nikPrefix] + strings.Repeat("*", len(seg)-nikPrefix)
+			redacted = true
+		}
+	}
+	if !redacted {
+		return path
+	}
+	return strings.Join(segments, "/")
+}
+
+func isNIK(s string) bool {
+	if len(s) != 16 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+// Package render writes response envelopes in the representation negotiated
+// with the client. JSON is the canonical form; CSV, XML and MessagePack are
+// derived from it, so every envelope (success, error, or middleware error)
+// is represented the same way in every format.
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+)
+
+// Supported representations and their ?format= names.
+const (
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatXML     = "xml"
+	FormatMsgPack = "msgpack"
+)
+
+const (
+	mimeCSV     = "text/csv"
+	mimeXML     = "application/xml"
+	mimeMsgPack = "application/msgpack"
+)
+
+var errNotAcceptable = errors.New("supported formats are application/json, text/csv, application/xml and application/msgpack")
+
+// offers lists the media types accepted in the Accept header, in order of
+// preference when the client has none. Aliases map to the same format.
+var offers = []struct {
+	mime   string
+	format string
+}{
+	{fiber.MIMEApplicationJSON, FormatJSON},
+	{mimeCSV, FormatCSV},
+	{mimeXML, FormatXML},
+	{fiber.MIMETextXML, FormatXML},
+	{mimeMsgPack, FormatMsgPack},
+	{"application/x-msgpack", FormatMsgPack},
+	{"application/vnd.msgpack", FormatMsgPack},
+}
+
+// contentTypes maps each format to the Content-Type it is served with.
+var contentTypes = map[string]string{
+	FormatJSON:    fiber.MIMEApplicationJSON,
+	FormatCSV:     mimeCSV + "; charset=utf-8",
+	FormatXML:     fiber.MIMEApplicationXMLCharsetUTF8,
+	FormatMsgPack: mimeMsgPack,
+}
+
+// ownFormatRoutes are the routes that use ?format= to select a
+// representation of their own, such as /export?format=ndjson. Their
+// responses, errors included, are negotiated from the Accept header alone.
+var ownFormatRoutes = map[string]bool{
+	"/export": true,
+}
+
+// Negotiate returns the format to respond with: the ?format= override when
+// present, otherwise the best match for the Accept header. It returns false
+// when the client accepts none of the supported formats.
+func Negotiate(c *fiber.Ctx) (string, bool) {
+	if f := strings.ToLower(c.Query("format")); f != "" && !ownsFormat(c) {
+		_, ok := contentTypes[f]
+		return f, ok
+	}
+	if c.Get(fiber.HeaderAccept) == "" {
+		return FormatJSON, true
+	}
+	mimes := make([]string, len(offers))
+	for i, o := range offers {
+		mimes[i] = o.mime
+	}
+	best := c.Accepts(mimes...)
+	for _, o := range offers {
+		if o.mime == best {
+			return o.format, true
+		}
+	}
+	return "", false
+}
+
+// ownsFormat reports whether the request is made to a route in
+// ownFormatRoutes. It goes by the path, so it holds in middleware running
+// before routing.
+func ownsFormat(c *fiber.Ctx) bool {
+	path := RoutePath(c)
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return ownFormatRoutes[path]
+}
+
+// RequestID returns the ID of the request, as sent in the X-Request-ID
+// response header.
+func RequestID(c *fiber.Ctx) string {
+	return c.GetRespHeader(fiber.HeaderXRequestID)
+}
+
+// Send writes body with the given status in the negotiated format, or a
+// 406 JSON error when no supported format is acceptable. On v2 routes body
+// is first converted to the v2 envelope, with errors served as problem
+// details. Error responses carry the request ID.
+func Send(c *fiber.Ctx, status int, body interface{}) error {
+	c.Vary(fiber.HeaderAccept)
+	format, ok := Negotiate(c)
+	if !ok {
+		format, status = FormatJSON, fiber.StatusNotAcceptable
+		body = model.NewErrorResponse(fiber.StatusNotAcceptable, "NOT_ACCEPTABLE", errNotAcceptable)
+	}
+	if errResp, isErr := body.(model.APIErrorResponse); isErr && errResp.RequestID == "" {
+		errResp.RequestID = RequestID(c)
+		body = errResp
+	}
+	body, problem := Envelope(VersionOf(c), body, c.Path())
+	if format == FormatJSON {
+		if problem {
+			return c.Status(status).JSON(body, MIMEProblemJSON)
+		}
+		return c.Status(status).JSON(body)
+	}
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	tree, err := decodeOrdered(raw)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatCSV:
+		err = writeCSV(c, &buf, tree)
+	case FormatXML:
+		err = writeXML(&buf, tree, problem)
+	case FormatMsgPack:
+		err = writeMsgPack(&buf, tree)
+	}
+	if err != nil {
+		return fmt.Errorf("render %s: %w", format, err)
+	}
+
+	contentType := contentTypes[format]
+	if problem && format == FormatXML {
+		contentType = MIMEProblemXML
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Status(status).Send(buf.Bytes())
+}
@@ -0,0 +1,98 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+)
+
+// Version is the API version a request was made against. It selects the
+// response envelope: V1 keeps the original status/message/data and
+// status/message/error bodies, V2 wraps data in model.Envelope and reports
+// errors as RFC 7807 problem details.
+type Version int
+
+// Supported API versions.
+const (
+	V1 Version = 1
+	V2 Version = 2
+)
+
+// Versions lists the supported API versions.
+var Versions = []Version{V1, V2}
+
+// MIME types of RFC 7807 problem details.
+const (
+	MIMEProblemJSON = "application/problem+json"
+	MIMEProblemXML  = "application/problem+xml"
+)
+
+// Prefixes returns the path prefixes the routes of v are mounted under. V1
+// is also served without a prefix, so existing clients keep working.
+func (v Version) Prefixes() []string {
+	if v == V2 {
+		return []string{"/v2"}
+	}
+	return []string{"", "/v1"}
+}
+
+// VersionOf returns the API version of the request, derived from its path
+// so it is known to middleware running before routing.
+func VersionOf(c *fiber.Ctx) Version {
+	if _, ok := trimVersion(c.Path(), "/v2"); ok {
+		return V2
+	}
+	return V1
+}
+
+// RoutePath returns the request path without its version prefix.
+func RoutePath(c *fiber.Ctx) string {
+	_, rest := splitVersion(c.Path())
+	return rest
+}
+
+// PathPrefix returns the version prefix the request was made under: "/v1",
+// "/v2", or "" for unversioned routes. Links in responses keep it.
+func PathPrefix(c *fiber.Ctx) string {
+	prefix, _ := splitVersion(c.Path())
+	return prefix
+}
+
+func splitVersion(path string) (string, string) {
+	for _, prefix := range []string{"/v1", "/v2"} {
+		if rest, ok := trimVersion(path, prefix); ok {
+			return prefix, rest
+		}
+	}
+	return "", path
+}
+
+func trimVersion(path, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return path, false
+	}
+	if rest == "" {
+		rest = "/"
+	}
+	return rest, true
+}
+
+// Envelope converts a v1 response body to the envelope of version v. It
+// reports whether the result is a problem details object. instance is the
+// request path recorded in problems. Bodies of other types are returned
+// unchanged.
+func Envelope(v Version, body interface{}, instance string) (interface{}, bool) {
+	if v != V2 {
+		return body, false
+	}
+	switch b := body.(type) {
+	case model.APIResponse:
+		return model.NewEnvelope(b), false
+	case model.APIErrorResponse:
+		return model.NewProblem(b, instance), true
+	}
+	return body, false
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
+)
+
+const (
+	// MaxBatchSize is the maximum number of codes accepted by one batch lookup.
+	MaxBatchSize = 1000
+
+	// batchCodesPerUnit is how many codes of a batch count as one request
+	// against the rate limit.
+	batchCodesPerUnit = 10
+)
+
+// BatchCost returns how many requests a batch of n codes counts as against
+// the rate limit: one per 10 codes, rounded up, and at least one.
+func BatchCost(n int) int {
+	if n <= batchCodesPerUnit {
+		return 1
+	}
+	return (n + batchCodesPerUnit - 1) / batchCodesPerUnit
+}
+
+// Ancestors returns the parents of the region with the given code, ordered
+// from the state down to the direct parent.
+func (s *LocationService) Ancestors(ctx context.Context, code string) ([]model.Region, error) {
+	_, span := tracer.Start(ctx, "LocationService.Ancestors", trace.WithAttributes(tracing.String("geoid.code", code)))
+	ancestors, err := s.ancestors(code)
+	tracing.End(span, err)
+	return ancestors, err
+}
+
+func (s *LocationService) ancestors(code string) ([]model.Region, error) {
+	codes := geoid.AncestorCodes(code)
+	ancestors := make([]model.Region, 0, len(codes))
+	for _, c := range codes {
+		parent, _, err := s.getRegion(c)
+		if err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, *parent)
+	}
+	return ancestors, nil
+}
+
+// BatchLookup resolves codes of mixed levels, returning one item per code in
+// input order. Invalid and unknown codes are reported per item rather than
+// failing the batch; only a dataset that cannot be loaded fails it, so no
+// valid code is ever reported as not found.
+func (s *LocationService) BatchLookup(ctx context.Context, codes []string, withAncestors bool) ([]model.BatchItem, error) {
+	_, span := tracer.Start(ctx, "LocationService.BatchLookup", trace.WithAttributes(
+		attribute.Int("geoid.batch_size", len(codes)),
+		attribute.Bool("geoid.include_ancestors", withAncestors),
+	))
+	if err := s.Load(); err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	defer span.End()
+
+	items := make([]model.BatchItem, len(codes))
+	for i, code := range codes {
+		item := model.BatchItem{Code: code}
+		region, level, err := s.getRegion(code)
+		switch {
+		case errors.Is(err, ErrInvalidCode):
+			item.Error = &model.BatchError{Code: "INVALID_CODE", Message: err.Error()}
+		case err != nil:
+			item.Error = &model.BatchError{Code: "NOT_FOUND", Message: err.Error()}
+		default:
+			item.Level = string(level)
+			item.Region = region
+			if withAncestors {
+				if item.Ancestors, err = s.ancestors(code); err != nil {
+					item.Error = &model.BatchError{Code: "NOT_FOUND", Message: err.Error()}
+					item.Region, item.Level = nil, ""
+				}
+			}
+		}
+		items[i] = item
+	}
+	return items, nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestBatchLookup(t *testing.T) {
+	svc := NewLocationService(filepath.Join("..", "..", "data"))
+	items, err := svc.BatchLookup(context.Background(), []string{"32.73.01", "99.99", "32.7"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		level, errCode string
+		ancestors      int
+	}{
+		{level: "district", ancestors: 2},
+		{errCode: "NOT_FOUND"},
+		{errCode: "INVALID_CODE"},
+	}
+	for i, w := range want {
+		item := items[i]
+		errCode := ""
+		if item.Error != nil {
+			errCode = item.Error.Code
+		}
+		if item.Level != w.level || errCode != w.errCode || len(item.Ancestors) != w.ancestors {
+			t.Errorf("item %d: got %+v, want level %q, error %q, %d ancestors", i, item, w.level, w.errCode, w.ancestors)
+		}
+	}
+}
+
+func TestBatchLookupLoadError(t *testing.T) {
+	svc := NewLocationService(filepath.Join(t.TempDir(), "missing"))
+	items, err := svc.BatchLookup(context.Background(), []string{"32.73"}, false)
+	if err == nil || items != nil {
+		t.Fatalf("got %+v, %v; want the load error rather than per-item errors", items, err)
+	}
+}
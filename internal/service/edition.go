@@ -0,0 +1,20 @@
+package service
+
+import (
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+)
+
+// Edition returns metadata about the dataset release in DataDir. Data
+// directories generated without edition.json report an unknown edition
+// dated by the modification time of states.json.
+func (s *LocationService) Edition() (*model.Edition, error) {
+	if err := s.Load(); err != nil {
+		return nil, err
+	}
+	edition := s.dataset.Edition()
+	return &model.Edition{
+		Name:      edition.Name,
+		Source:    edition.Source,
+		UpdatedAt: edition.UpdatedAt,
+	}, nil
+}
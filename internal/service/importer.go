@@ -0,0 +1,196 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+)
+
+// WilayahSQLURL is the upstream wilayah.sql the dataset is generated from.
+const WilayahSQLURL = "https://raw.githubusercontent.com/cahyadsn/wilayah/master/db/wilayah.sql"
+
+var (
+	// wilayahRow matches one ('code','name') tuple of an INSERT statement.
+	// Quotes inside names are escaped as '' or \'.
+	wilayahRow = regexp.MustCompile(`\(\s*'((?:[^'\\]|\\.|'')*)'\s*,\s*'((?:[^'\\]|\\.|'')*)'\s*\)`)
+	// wilayahNote and wilayahLastEdit match the edition in the file header,
+	// e.g. "note     : Data Kode Wilayah sesuai Kepmendagri No 300.2.2-2138
+	// Tahun 2025" and "last edit: 2025-10-01 08:45:08".
+	wilayahNote     = regexp.MustCompile(`note\s*:\s*Data Kode Wilayah sesuai (.+)`)
+	wilayahLastEdit = regexp.MustCompile(`last edit:\s*(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})`)
+	// wib is Western Indonesia Time, the zone of the header timestamps.
+	wib = time.FixedZone("WIB", 7*60*60)
+)
+
+// Dataset is a full set of regions, as imported from wilayah.sql, ready to
+// be written to a data directory.
+type Dataset struct {
+	Edition model.Edition
+	States  []model.Region
+	// Children maps the code of every city, district and village parent to
+	// its children, in source order.
+	Children map[string][]model.Region
+	// Skipped counts rows whose code is not a valid region code.
+	Skipped int
+}
+
+// ParseWilayahSQL reads a wilayah.sql dump and returns its regions, in the
+// same layout as the data directory. source is recorded as the edition's
+// source.
+func ParseWilayahSQL(r io.Reader, source string) (*Dataset, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dataset{
+		Edition:  model.Edition{Source: source},
+		Children: make(map[string][]model.Region),
+	}
+	if m := wilayahNote.FindSubmatch(content); m != nil {
+		d.Edition.Name = strings.TrimSpace(string(m[1]))
+	}
+	if m := wilayahLastEdit.FindSubmatch(content); m != nil {
+		if d.Edition.UpdatedAt, err = time.ParseInLocation(time.DateTime, string(m[1]), wib); err != nil {
+			return nil, fmt.Errorf("parse last edit: %w", err)
+		}
+	}
+
+	for _, m := range wilayahRow.FindAllSubmatch(content, -1) {
+		region := model.Region{
+			Code:  strings.TrimSpace(unquoteSQL(m[1])),
+			Value: strings.TrimSpace(unquoteSQL(m[2])),
+		}
+		level, err := LevelOf(region.Code)
+		if err != nil {
+			d.Skipped++
+			continue
+		}
+		if level == LevelState {
+			d.States = append(d.States, region)
+			continue
+		}
+		parent := ParentCode(region.Code)
+		d.Children[parent] = append(d.Children[parent], region)
+	}
+	if len(d.States) == 0 {
+		return nil, fmt.Errorf("%w: no regions found in %s", ErrInvalidDataset, source)
+	}
+	return d, nil
+}
+
+// unquoteSQL removes the escaping of a single-quoted SQL string.
+func unquoteSQL(s []byte) string {
+	if !bytes.ContainsAny(s, `'\`) {
+		return string(s)
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if (s[i] == '\\' || s[i] == '\'') && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// Counts returns the number of regions per level.
+func (d *Dataset) Counts() model.RegionCounts {
+	counts := model.RegionCounts{States: len(d.States)}
+	for _, children := range d.Children {
+		if len(children) == 0 {
+			continue
+		}
+		switch level, _ := LevelOf(children[0].Code); level {
+		case LevelCity:
+			counts.Cities += len(children)
+		case LevelDistrict:
+			counts.Districts += len(children)
+		case LevelVillage:
+			counts.Villages += len(children)
+		}
+	}
+	return counts
+}
+
+// WriteDir writes the dataset to dir: edition.json, states.json and one
+// file per parent under cities/, districts/ and villages/. The files are
+// written to a temporary directory next to dir and checked with Validate
+// before they replace dir, so readers never see a partial or invalid
+// dataset. Like Validate it returns the number of orphan villages.
+func (d *Dataset) WriteDir(dir string) (orphans int, err error) {
+	dir = filepath.Clean(dir)
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+"-import-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(tmp)
+		}
+	}()
+	if err := os.Chmod(tmp, 0o755); err != nil {
+		return 0, err
+	}
+	if err := d.write(tmp); err != nil {
+		return 0, err
+	}
+	if orphans, err = NewLocationService(tmp).Validate(); err != nil {
+		return 0, err
+	}
+
+	// Swap the new dataset in, keeping the old one until it succeeds
+	old := tmp + "-old"
+	if err := os.Rename(dir, old); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		os.Rename(old, dir)
+		return 0, err
+	}
+	return orphans, os.RemoveAll(old)
+}
+
+// write writes the dataset files to the empty directory dir.
+func (d *Dataset) write(dir string) error {
+	for _, level := range []Level{LevelCity, LevelDistrict, LevelVillage} {
+		if err := os.Mkdir(filepath.Join(dir, level.Plural()), 0o755); err != nil {
+			return err
+		}
+	}
+	if err := writeJSON(filepath.Join(dir, "edition.json"), d.Edition); err != nil {
+		return err
+	}
+	if err := writeJSON(filepath.Join(dir, "states.json"), d.States); err != nil {
+		return err
+	}
+	for parent, children := range d.Children {
+		level, _ := LevelOf(parent)
+		if err := writeJSON(filepath.Join(dir, level.Child().Plural(), parent+".json"), children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSON writes v to path as indented JSON without escaping HTML
+// characters, in the format of the original extraction script.
+func writeJSON(path string, v interface{}) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return os.WriteFile(path, bytes.TrimSuffix(buf.Bytes(), []byte("\n")), 0o644)
+}
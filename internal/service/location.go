@@ -1,143 +1,172 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
 )
 
+// tracer records service and data access spans.
+var tracer = otel.Tracer("github.com/ikhsanfalakh/geo-id/internal/service")
+
 type LocationService struct {
 	DataDir string
+	// PastDataDirs are data directories of past editions, newest first,
+	// used to recognise NIK region prefixes that no longer exist.
+	PastDataDirs []string
+
+	// dataset holds every region in DataDir and the past editions in
+	// PastDataDirs, loaded once by Load.
+	loadOnce sync.Once
+	dataset  *geoid.Dataset
+	past     []*geoid.Dataset
+	loadErr  error
 }
 
 func NewLocationService(dataDir string) *LocationService {
 	return &LocationService{DataDir: dataDir}
 }
 
-func (s *LocationService) readJSON(path string, v interface{}) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	return json.NewDecoder(file).Decode(v)
+// Load reads every region in DataDir into memory, along with the dataset
+// edition and the past editions in PastDataDirs. It is safe to call
+// repeatedly; only the first call reads the files. Lookups call it lazily,
+// but calling it at startup keeps the first request fast.
+func (s *LocationService) Load() error {
+	s.loadOnce.Do(func() {
+		start := time.Now()
+		if s.dataset, s.loadErr = geoid.LoadDir(s.DataDir); s.loadErr != nil {
+			return
+		}
+		if s.dataset.Edition().Name == geoid.UnknownEdition {
+			slog.Warn("Dataset has no edition.json, reporting edition as unknown", "data_dir", s.DataDir)
+		}
+		for _, dir := range s.PastDataDirs {
+			past, err := geoid.LoadDir(dir)
+			if err != nil {
+				s.loadErr = fmt.Errorf("past edition: %w", err)
+				return
+			}
+			s.past = append(s.past, past)
+		}
+		slog.Debug("Dataset indexed", "data_dir", s.DataDir, "regions", s.dataset.Len(), "duration", time.Since(start).Round(time.Millisecond).String())
+	})
+	return s.loadErr
 }
 
-func (s *LocationService) GetStates() ([]model.Region, error) {
-	var regions []model.Region
-	path := filepath.Join(s.DataDir, "states.json")
-	if err := s.readJSON(path, &regions); err != nil {
+// Dataset returns the loaded dataset, loading it first if needed.
+func (s *LocationService) Dataset() (*geoid.Dataset, error) {
+	if err := s.Load(); err != nil {
 		return nil, err
 	}
-	return regions, nil
+	return s.dataset, nil
 }
 
-func (s *LocationService) GetState(code string) (*model.Region, error) {
-	states, err := s.GetStates()
-	if err != nil {
+// lookup finds a region of the given level in the dataset.
+func (s *LocationService) lookup(code string, level Level) (*model.Region, error) {
+	if err := s.Load(); err != nil {
 		return nil, err
 	}
-	for _, state := range states {
-		if state.Code == code {
-			return &state, nil
-		}
+	if l, err := LevelOf(code); err != nil || l != level {
+		return nil, fmt.Errorf("%s not found", level)
+	}
+	r, ok := s.dataset.Lookup(code)
+	if !ok {
+		return nil, fmt.Errorf("%s not found", level)
 	}
-	return nil, fmt.Errorf("state not found")
+	region := toModel(r)
+	return &region, nil
 }
 
-func (s *LocationService) GetCities(stateCode string) ([]model.Region, error) {
-	var regions []model.Region
-	path := filepath.Join(s.DataDir, "cities", stateCode+".json")
-	if err := s.readJSON(path, &regions); err != nil {
+// listChildren returns the children of the region with the given code,
+// which must be of the given level, or the states for an empty code.
+func (s *LocationService) listChildren(code string, level Level) ([]model.Region, error) {
+	if err := s.Load(); err != nil {
 		return nil, err
 	}
-	return regions, nil
-}
-
-func (s *LocationService) GetCity(code string) (*model.Region, error) {
-	// This is inefficient without a mapping or knowing the parent state.
-	// The original API might have a better way or just iterates everything?
-	// Based on file structure cities/[state_id].json, we can't easily find a city by ID without checking all state files.
-	// However, the original API endpoint /cities/:id implies it's possible.
-	// For now, let's implement a search across all available city files if we have to, 
-	// OR we assume the user provides parent ID in a real app, but the API spec says /cities/:id.
-	
-	// Optimization: In a real app we would load this into memory or a DB.
-	// For this file-based approach, we might need to walk the directory.
-	
-	files, err := filepath.Glob(filepath.Join(s.DataDir, "cities", "*.json"))
+	if code == "" {
+		return toModels(s.dataset.States()), nil
+	}
+	if _, err := s.lookup(code, level); err != nil {
+		return nil, err
+	}
+	children, err := s.dataset.Children(code)
 	if err != nil {
 		return nil, err
 	}
+	return toModels(children), nil
+}
 
-	for _, file := range files {
-		var regions []model.Region
-		if err := s.readJSON(file, &regions); err == nil {
-			for _, r := range regions {
-				if r.Code == code {
-					return &r, nil
-				}
-			}
-		}
-	}
-	return nil, fmt.Errorf("city not found")
+// toModel converts a library region to the API model.
+func toModel(r geoid.Region) model.Region {
+	return model.Region{Code: r.Code, Value: r.Name}
 }
 
-func (s *LocationService) GetDistricts(cityCode string) ([]model.Region, error) {
-	var regions []model.Region
-	path := filepath.Join(s.DataDir, "districts", cityCode+".json")
-	if err := s.readJSON(path, &regions); err != nil {
-		return nil, err
+func toModels(regions []geoid.Region) []model.Region {
+	out := make([]model.Region, len(regions))
+	for i, r := range regions {
+		out[i] = toModel(r)
 	}
-	return regions, nil
+	return out
 }
 
-func (s *LocationService) GetDistrict(code string) (*model.Region, error) {
-	files, err := filepath.Glob(filepath.Join(s.DataDir, "districts", "*.json"))
-	if err != nil {
-		return nil, err
+// list returns the children of the region with the given code inside a
+// span named after the service method.
+func (s *LocationService) list(ctx context.Context, name, code string, level Level) ([]model.Region, error) {
+	_, span := tracer.Start(ctx, name)
+	if code != "" {
+		span.SetAttributes(tracing.String("geoid.code", code))
 	}
+	regions, err := s.listChildren(code, level)
+	tracing.End(span, err)
+	return regions, err
+}
 
-	for _, file := range files {
-		var regions []model.Region
-		if err := s.readJSON(file, &regions); err == nil {
-			for _, r := range regions {
-				if r.Code == code {
-					return &r, nil
-				}
-			}
-		}
-	}
-	return nil, fmt.Errorf("district not found")
+// find looks up a region in the dataset inside a span named after the
+// service method.
+func (s *LocationService) find(ctx context.Context, name, code string, level Level) (*model.Region, error) {
+	_, span := tracer.Start(ctx, name, trace.WithAttributes(tracing.String("geoid.code", code)))
+	region, err := s.lookup(code, level)
+	tracing.End(span, err)
+	return region, err
 }
 
-func (s *LocationService) GetVillages(districtCode string) ([]model.Region, error) {
-	var regions []model.Region
-	path := filepath.Join(s.DataDir, "villages", districtCode+".json")
-	if err := s.readJSON(path, &regions); err != nil {
-		return nil, err
-	}
-	return regions, nil
+func (s *LocationService) GetStates(ctx context.Context) ([]model.Region, error) {
+	return s.list(ctx, "LocationService.GetStates", "", "")
 }
 
-func (s *LocationService) GetVillage(code string) (*model.Region, error) {
-	files, err := filepath.Glob(filepath.Join(s.DataDir, "villages", "*.json"))
-	if err != nil {
-		return nil, err
-	}
+func (s *LocationService) GetState(ctx context.Context, code string) (*model.Region, error) {
+	return s.find(ctx, "LocationService.GetState", code, LevelState)
+}
 
-	for _, file := range files {
-		var regions []model.Region
-		if err := s.readJSON(file, &regions); err == nil {
-			for _, r := range regions {
-				if r.Code == code {
-					return &r, nil
-				}
-			}
-		}
-	}
-	return nil, fmt.Errorf("village not found")
+func (s *LocationService) GetCities(ctx context.Context, stateCode string) ([]model.Region, error) {
+	return s.list(ctx, "LocationService.GetCities", stateCode, LevelState)
+}
+
+func (s *LocationService) GetCity(ctx context.Context, code string) (*model.Region, error) {
+	return s.find(ctx, "LocationService.GetCity", code, LevelCity)
+}
+
+func (s *LocationService) GetDistricts(ctx context.Context, cityCode string) ([]model.Region, error) {
+	return s.list(ctx, "LocationService.GetDistricts", cityCode, LevelCity)
+}
+
+func (s *LocationService) GetDistrict(ctx context.Context, code string) (*model.Region, error) {
+	return s.find(ctx, "LocationService.GetDistrict", code, LevelDistrict)
+}
+
+func (s *LocationService) GetVillages(ctx context.Context, districtCode string) ([]model.Region, error) {
+	return s.list(ctx, "LocationService.GetVillages", districtCode, LevelDistrict)
+}
+
+func (s *LocationService) GetVillage(ctx context.Context, code string) (*model.Region, error) {
+	return s.find(ctx, "LocationService.GetVillage", code, LevelVillage)
 }
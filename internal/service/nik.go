@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
+)
+
+// DecodeNIK checks the structure of a NIK and resolves its region prefix
+// against the dataset and then the past editions in PastDataDirs. It fails
+// with geoid.ErrInvalidNIK for a malformed NIK. The NIK is not recorded on
+// the span.
+func (s *LocationService) DecodeNIK(ctx context.Context, nik string) (*geoid.NIKRegion, error) {
+	_, span := tracer.Start(ctx, "LocationService.DecodeNIK")
+	decoded, err := s.decodeNIK(nik)
+	tracing.End(span, err)
+	return decoded, err
+}
+
+func (s *LocationService) decodeNIK(nik string) (*geoid.NIKRegion, error) {
+	if err := s.Load(); err != nil {
+		return nil, err
+	}
+	return s.dataset.DecodeNIK(nik, s.past...)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+)
+
+const (
+	// SortByCode orders regions by their administrative code (file order).
+	SortByCode = "code"
+	// SortByName orders regions by name using Indonesian collation.
+	SortByName = "name"
+
+	// MaxListLimit caps the page size a client may request.
+	MaxListLimit = 1000
+)
+
+// ErrInvalidQuery is returned when list query parameters cannot be applied.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ListQuery holds the pagination, sorting and filtering options for list endpoints.
+// A zero Limit means "no limit" so that existing clients keep receiving the whole list.
+type ListQuery struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   string
+	Query  string
+}
+
+// ListResult is a page of regions together with its pagination metadata.
+type ListResult struct {
+	Items      []model.Region
+	Pagination model.Pagination
+}
+
+// cursorToken is the decoded form of an opaque pagination cursor.
+type cursorToken struct {
+	Offset      int    `json:"o"`
+	Fingerprint string `json:"f"`
+}
+
+// Apply filters, sorts and slices regions according to the query.
+func (q ListQuery) Apply(regions []model.Region) (*ListResult, error) {
+	if err := q.validate(); err != nil {
+		return nil, err
+	}
+
+	offset := q.Offset
+	if q.Cursor != "" {
+		token, err := decodeCursor(q.Cursor)
+		if err != nil || token.Fingerprint != q.fingerprint() {
+			return nil, fmt.Errorf("%w: cursor does not match this query", ErrInvalidQuery)
+		}
+		offset = token.Offset
+	}
+
+	items := filterByName(regions, q.Query)
+	sortRegions(items, q.Sort)
+
+	total := len(items)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if q.Limit > 0 && offset+q.Limit < total {
+		end = offset + q.Limit
+	}
+
+	result := &ListResult{
+		Items: items[offset:end],
+		Pagination: model.Pagination{
+			Total:  total,
+			Limit:  q.Limit,
+			Offset: offset,
+		},
+	}
+	if end < total {
+		result.Pagination.NextCursor = encodeCursor(cursorToken{Offset: end, Fingerprint: q.fingerprint()})
+	}
+	if result.Items == nil {
+		result.Items = []model.Region{}
+	}
+	return result, nil
+}
+
+func (q ListQuery) validate() error {
+	switch q.Sort {
+	case "", SortByCode, SortByName:
+	default:
+		return fmt.Errorf("%w: sort must be one of %q or %q", ErrInvalidQuery, SortByCode, SortByName)
+	}
+	if q.Limit < 0 || q.Limit > MaxListLimit {
+		return fmt.Errorf("%w: limit must be between 0 and %d; 0 returns every region", ErrInvalidQuery, MaxListLimit)
+	}
+	if q.Offset < 0 {
+		return fmt.Errorf("%w: offset must not be negative", ErrInvalidQuery)
+	}
+	if q.Cursor != "" && q.Offset != 0 {
+		return fmt.Errorf("%w: cursor and offset cannot be combined", ErrInvalidQuery)
+	}
+	return nil
+}
+
+// fingerprint ties a cursor to the sort and filter it was issued for, so a
+// cursor cannot be replayed against a differently ordered result set.
+func (q ListQuery) fingerprint() string {
+	sortKey := q.Sort
+	if sortKey == "" {
+		sortKey = SortByCode
+	}
+	return sortKey + "|" + strings.ToLower(strings.TrimSpace(q.Query))
+}
+
+func encodeCursor(t cursorToken) string {
+	raw, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(cursor string) (cursorToken, error) {
+	var t cursorToken
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return t, err
+	}
+	if t.Offset < 0 {
+		return t, errors.New("negative cursor offset")
+	}
+	return t, nil
+}
+
+// filterByName returns a copy of regions whose name contains q (case-insensitive).
+func filterByName(regions []model.Region, q string) []model.Region {
+	needle := strings.ToLower(strings.TrimSpace(q))
+	out := make([]model.Region, 0, len(regions))
+	for _, r := range regions {
+		if needle == "" || strings.Contains(strings.ToLower(r.Value), needle) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func sortRegions(regions []model.Region, by string) {
+	if by == SortByName {
+		// Collators are not safe for concurrent use, so build one per call.
+		col := collate.New(language.Indonesian, collate.IgnoreCase, collate.Loose)
+		sort.SliceStable(regions, func(i, j int) bool {
+			return col.CompareString(regions[i].Value, regions[j].Value) < 0
+		})
+		return
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		return compareCodes(regions[i].Code, regions[j].Code) < 0
+	})
+}
+
+// compareCodes orders dotted region codes segment by segment numerically,
+// so that "11.10" sorts after "11.9".
+func compareCodes(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if len(as[i]) != len(bs[i]) {
+			return len(as[i]) - len(bs[i])
+		}
+		if c := strings.Compare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return len(as) - len(bs)
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+)
+
+// testRegions are listed out of code order, with "11.10" after "11.9"
+// numerically but before it as a string.
+var testRegions = []model.Region{
+	{Code: "11.10", Value: "Kabupaten Aceh Singkil"},
+	{Code: "11.1", Value: "Kabupaten Aceh Selatan"},
+	{Code: "11.9", Value: "Kabupaten Simeulue"},
+	{Code: "11.71", Value: "Kota Banda Aceh"},
+	{Code: "11.72", Value: "Kota Sabang"},
+}
+
+func codes(regions []model.Region) []string {
+	out := make([]string, len(regions))
+	for i, r := range regions {
+		out[i] = r.Code
+	}
+	return out
+}
+
+func TestListQueryApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      ListQuery
+		wantCodes  []string
+		wantTotal  int
+		wantOffset int
+		wantNext   bool
+	}{
+		{
+			name:      "no limit returns everything in code order",
+			query:     ListQuery{},
+			wantCodes: []string{"11.1", "11.9", "11.10", "11.71", "11.72"},
+			wantTotal: 5,
+		},
+		{
+			name:      "first page",
+			query:     ListQuery{Limit: 2},
+			wantCodes: []string{"11.1", "11.9"},
+			wantTotal: 5,
+			wantNext:  true,
+		},
+		{
+			name:       "last page",
+			query:      ListQuery{Limit: 2, Offset: 4},
+			wantCodes:  []string{"11.72"},
+			wantTotal:  5,
+			wantOffset: 4,
+		},
+		{
+			name:       "offset beyond total",
+			query:      ListQuery{Limit: 2, Offset: 50},
+			wantCodes:  []string{},
+			wantTotal:  5,
+			wantOffset: 5,
+		},
+		{
+			name:      "filter ignores case and spaces",
+			query:     ListQuery{Query: "  ACEH "},
+			wantCodes: []string{"11.1", "11.10", "11.71"},
+			wantTotal: 3,
+		},
+		{
+			name:      "filter matching nothing",
+			query:     ListQuery{Query: "jawa"},
+			wantCodes: []string{},
+		},
+		{
+			name:      "sort by name",
+			query:     ListQuery{Sort: SortByName},
+			wantCodes: []string{"11.1", "11.10", "11.9", "11.71", "11.72"},
+			wantTotal: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := slices.Clone(testRegions)
+			result, err := tt.query.Apply(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := codes(result.Items); !slices.Equal(got, tt.wantCodes) {
+				t.Fatalf("got %v, want %v", got, tt.wantCodes)
+			}
+			p := result.Pagination
+			if p.Total != tt.wantTotal || p.Offset != tt.wantOffset || p.Limit != tt.query.Limit || (p.NextCursor != "") != tt.wantNext {
+				t.Fatalf("got pagination %+v, want total %d, offset %d, next cursor %v", p, tt.wantTotal, tt.wantOffset, tt.wantNext)
+			}
+			if !slices.Equal(codes(input), codes(testRegions)) {
+				t.Fatal("Apply reordered its input")
+			}
+		})
+	}
+}
+
+func TestListQueryCursor(t *testing.T) {
+	q := ListQuery{Limit: 2, Sort: SortByName, Query: "kabupaten"}
+	var got []string
+	pages := 0
+	for {
+		result, err := q.Apply(testRegions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, codes(result.Items)...)
+		pages++
+		if result.Pagination.NextCursor == "" {
+			break
+		}
+		q.Cursor = result.Pagination.NextCursor
+	}
+	if want := []string{"11.1", "11.10", "11.9"}; !slices.Equal(got, want) || pages != 2 {
+		t.Fatalf("got %v in %d pages, want %v in 2", got, pages, want)
+	}
+
+	first, err := ListQuery{Limit: 2}.Apply(testRegions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor := first.Pagination.NextCursor
+
+	// The page size may change between pages; the sort and filter may not.
+	if _, err := (ListQuery{Limit: 3, Cursor: cursor}).Apply(testRegions); err != nil {
+		t.Fatalf("cursor with another limit: %v", err)
+	}
+	invalid := []struct {
+		name  string
+		query ListQuery
+	}{
+		{"other sort", ListQuery{Limit: 2, Cursor: cursor, Sort: SortByName}},
+		{"other filter", ListQuery{Limit: 2, Cursor: cursor, Query: "aceh"}},
+		{"malformed", ListQuery{Limit: 2, Cursor: "not a cursor"}},
+		{"negative offset", ListQuery{Limit: 2, Cursor: encodeCursor(cursorToken{Offset: -1, Fingerprint: "code|"})}},
+		{"with offset", ListQuery{Limit: 2, Cursor: cursor, Offset: 2}},
+	}
+	for _, tt := range invalid {
+		if _, err := tt.query.Apply(testRegions); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: got %v, want ErrInvalidQuery", tt.name, err)
+		}
+	}
+}
+
+func TestListQueryValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   ListQuery
+		wantErr string
+	}{
+		{"negative limit", ListQuery{Limit: -1}, "between 0 and 1000; 0 returns every region"},
+		{"limit above maximum", ListQuery{Limit: MaxListLimit + 1}, "between 0 and 1000"},
+		{"negative offset", ListQuery{Offset: -1}, "offset must not be negative"},
+		{"unknown sort", ListQuery{Sort: "population"}, "sort must be one of"},
+		{"cursor and offset", ListQuery{Cursor: "x", Offset: 1}, "cursor and offset cannot be combined"},
+	}
+	for _, tt := range tests {
+		_, err := tt.query.Apply(testRegions)
+		if !errors.Is(err, ErrInvalidQuery) || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got %v, want ErrInvalidQuery mentioning %q", tt.name, err, tt.wantErr)
+		}
+	}
+	if _, err := (ListQuery{Limit: MaxListLimit}).Apply(testRegions); err != nil {
+		t.Errorf("maximum limit: %v", err)
+	}
+}
+
+func TestSortByNameCollation(t *testing.T) {
+	regions := []model.Region{
+		{Code: "1", Value: "Fakfak"},
+		{Code: "2", Value: "éretan"},
+		{Code: "3", Value: "Depok"},
+		{Code: "4", Value: "aceh"},
+		{Code: "5", Value: "Bogor"},
+	}
+	result, err := ListQuery{Sort: SortByName}.Apply(regions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Byte order would put "aceh" after the capitals and "éretan" last.
+	if got, want := codes(result.Items), []string{"4", "5", "3", "2", "1"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
+)
+
+// Level identifies the administrative level of a region.
+type Level = geoid.Level
+
+const (
+	LevelState    = geoid.LevelState
+	LevelCity     = geoid.LevelCity
+	LevelDistrict = geoid.LevelDistrict
+	LevelVillage  = geoid.LevelVillage
+)
+
+// MaxTreeNodes caps how many descendant regions a single tree or expansion
+// may contain, so one request cannot pull the whole dataset.
+const MaxTreeNodes = 10000
+
+var (
+	// ErrInvalidCode is returned when a code does not match any level's format.
+	ErrInvalidCode = geoid.ErrInvalidCode
+	// ErrTreeTooLarge is returned when a tree would exceed MaxTreeNodes.
+	ErrTreeTooLarge = fmt.Errorf("expansion exceeds %d regions", MaxTreeNodes)
+)
+
+// LevelOf infers the administrative level from the shape of a code:
+// "11" (state), "11.01" (city), "11.01.01" (district), "11.01.01.2001" (village).
+func LevelOf(code string) (Level, error) {
+	return geoid.LevelOf(code)
+}
+
+// ChildLevel returns the level directly below l, or "" for villages.
+func ChildLevel(l Level) Level {
+	return l.Child()
+}
+
+// ParentCode returns the code of the region directly above code, or "" for
+// states.
+func ParentCode(code string) string {
+	return geoid.ParentCode(code)
+}
+
+// GetRegion looks up a region of any level by its code.
+func (s *LocationService) GetRegion(ctx context.Context, code string) (*model.Region, Level, error) {
+	_, span := tracer.Start(ctx, "LocationService.GetRegion", trace.WithAttributes(tracing.String("geoid.code", code)))
+	region, level, err := s.getRegion(code)
+	tracing.End(span, err)
+	return region, level, err
+}
+
+func (s *LocationService) getRegion(code string) (*model.Region, Level, error) {
+	level, err := LevelOf(code)
+	if err != nil {
+		return nil, "", err
+	}
+	region, err := s.lookup(code, level)
+	if err != nil {
+		return nil, "", err
+	}
+	return region, level, nil
+}
+
+// GetChildren returns the direct children of the region with the given code.
+// Regions without children (including villages) yield an empty list.
+func (s *LocationService) GetChildren(ctx context.Context, code string) ([]model.Region, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.GetChildren", trace.WithAttributes(tracing.String("geoid.code", code)))
+	children, err := s.children(ctx, code)
+	tracing.End(span, err)
+	return children, err
+}
+
+func (s *LocationService) children(ctx context.Context, code string) ([]model.Region, error) {
+	if _, err := LevelOf(code); err != nil {
+		return nil, err
+	}
+	if err := s.Load(); err != nil {
+		return nil, err
+	}
+	children, err := s.dataset.Children(code)
+	if errors.Is(err, geoid.ErrNotFound) {
+		return []model.Region{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toModels(children), nil
+}
+
+// GetTree returns the region with the given code and its descendants nested
+// depth levels deep. It fails with ErrTreeTooLarge once more than
+// MaxTreeNodes descendants would be included.
+func (s *LocationService) GetTree(ctx context.Context, code string, depth int) (*model.Region, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.GetTree", trace.WithAttributes(
+		tracing.String("geoid.code", code),
+		attribute.Int("geoid.depth", depth),
+	))
+	root, err := s.tree(ctx, code, depth)
+	tracing.End(span, err)
+	return root, err
+}
+
+func (s *LocationService) tree(ctx context.Context, code string, depth int) (*model.Region, error) {
+	root, _, err := s.getRegion(code)
+	if err != nil {
+		return nil, err
+	}
+	budget := MaxTreeNodes
+	if err := s.expand(ctx, root, depth, &budget); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (s *LocationService) expand(ctx context.Context, node *model.Region, depth int, budget *int) error {
+	if depth <= 0 {
+		return nil
+	}
+	children, err := s.children(ctx, node.Code)
+	if err != nil {
+		return err
+	}
+	*budget -= len(children)
+	if *budget < 0 {
+		return ErrTreeTooLarge
+	}
+	for i := range children {
+		if err := s.expand(ctx, &children[i], depth-1, budget); err != nil {
+			return err
+		}
+	}
+	node.Children = children
+	return nil
+}
+
+// ExpandDepth converts an expansion path like "cities.districts" into the
+// number of child levels to nest below a region of level from. Each segment
+// must name the next level down; an empty path means no expansion.
+func ExpandDepth(expand string, from Level) (int, error) {
+	if expand == "" {
+		return 0, nil
+	}
+	level := from
+	segments := strings.Split(expand, ".")
+	for _, seg := range segments {
+		level = ChildLevel(level)
+		if level == "" || seg != level.Plural() {
+			return 0, fmt.Errorf("%w: cannot expand %q below a %s", ErrInvalidQuery, expand, from)
+		}
+	}
+	return len(segments), nil
+}
+
+// RegionType returns the administrative type of a region: provinsi,
+// kabupaten or kota, kecamatan, and desa or kelurahan.
+func RegionType(r model.Region) string {
+	return geoid.Region{Code: r.Code, Name: r.Value}.Type()
+}
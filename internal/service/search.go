@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
+)
+
+// Search returns up to limit regions whose name contains q (case-insensitive),
+// ordered by code. When level is set only regions of that level match.
+func (s *LocationService) Search(ctx context.Context, q string, level Level, limit int) ([]model.Region, error) {
+	_, span := tracer.Start(ctx, "LocationService.Search", trace.WithAttributes(
+		tracing.String("geoid.query", q),
+		tracing.String("geoid.level", string(level)),
+	))
+	matches, err := s.search(q, level, limit)
+	tracing.End(span, err)
+	return matches, err
+}
+
+func (s *LocationService) search(q string, level Level, limit int) ([]model.Region, error) {
+	if err := s.Load(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return []model.Region{}, nil
+	}
+	return toModels(s.dataset.Search(q, geoid.SearchOptions{Level: level, Limit: limit})), nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
+)
+
+// ErrInvalidDataset is returned by Validate when the data directory is
+// incomplete or inconsistent.
+var ErrInvalidDataset = geoid.ErrInvalidDataset
+
+// Counts returns the number of loaded regions per level.
+func (s *LocationService) Counts() (model.RegionCounts, error) {
+	if err := s.Load(); err != nil {
+		return model.RegionCounts{}, err
+	}
+	counts := s.dataset.Counts()
+	return model.RegionCounts{
+		States:    counts.States,
+		Cities:    counts.Cities,
+		Districts: counts.Districts,
+		Villages:  counts.Villages,
+	}, nil
+}
+
+// Validate loads the dataset and checks it with geoid.Dataset.Validate,
+// returning the number of villages whose district is missing from the
+// source data.
+func (s *LocationService) Validate() (int, error) {
+	if err := s.Load(); err != nil {
+		return 0, err
+	}
+	return s.dataset.Validate()
+}
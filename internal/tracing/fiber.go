@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ikhsanfalakh/geo-id/internal/metrics"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+)
+
+// tracer records the HTTP server, middleware and handler spans.
+var tracer = otel.Tracer("github.com/ikhsanfalakh/geo-id/internal/tracing")
+
+// Middleware returns a Fiber handler that:
+//  1. Continues the trace in the request's traceparent header, if any.
+//  2. Starts a server span and stores it in the request's user context, so
+//     spans started by middleware, handlers and services become its children.
+//  3. Names the span after the route pattern once the response is written
+//     (e.g. "GET /v2/states/:id") and records the status.
+//
+// It must be registered after the request ID middleware and before the
+// access log, which reads the trace ID.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !enabled {
+			return c.Next()
+		}
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+		ctx, span := tracer.Start(ctx, strings.Clone(c.Method()),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				String("http.request.method", c.Method()),
+				String("url.path", render.RedactPath(c.Path())),
+				String("client.address", c.IP()),
+				String("request.id", c.GetRespHeader(fiber.HeaderXRequestID)),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		status := c.Response().StatusCode()
+		route := metrics.RoutePattern(c, status)
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(
+			String("http.route", route),
+			attribute.Int("http.response.status_code", status),
+		)
+		if err != nil {
+			span.RecordError(err)
+		}
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		return err
+	}
+}
+
+// Wrap returns h wrapped in a span named name, such as "middleware.cache"
+// or "handler.GetStates". A middleware span also covers the rest of the
+// chain it runs. When tracing is disabled h is returned unchanged.
+func Wrap(name string, h fiber.Handler) fiber.Handler {
+	if !enabled {
+		return h
+	}
+	return func(c *fiber.Ctx) error {
+		parent := c.UserContext()
+		ctx, span := tracer.Start(parent, name)
+		c.SetUserContext(ctx)
+		err := h(c)
+		c.SetUserContext(parent)
+		End(span, err)
+		return err
+	}
+}
+
+// TraceID returns the ID of the trace the request belongs to, or "" when it
+// is not traced.
+func TraceID(c *fiber.Ctx) string {
+	sc := trace.SpanContextFromContext(c.UserContext())
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// headerCarrier reads propagation headers from a Fiber request.
+type headerCarrier struct{ c *fiber.Ctx }
+
+func (h headerCarrier) Get(key string) string { return h.c.Get(key) }
+
+func (h headerCarrier) Set(key, value string) { h.c.Request().Header.Set(key, value) }
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
@@ -0,0 +1,139 @@
+// Package tracing configures OpenTelemetry tracing: the tracer provider and
+// its exporter, W3C Trace Context propagation, and spans around Fiber
+// middleware and handlers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Supported exporters.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects and configures the span exporter.
+type Config struct {
+	// Exporter is one of ExporterNone (the default), ExporterStdout,
+	// ExporterFile or ExporterOTLP. OTLP exports over HTTP and is configured
+	// by the standard OTEL_EXPORTER_OTLP_* environment variables.
+	Exporter string
+	// File is the path ExporterFile appends OTLP JSON lines to.
+	File string
+
+	ServiceName    string
+	ServiceVersion string
+}
+
+// enabled reports whether Setup installed an exporting tracer provider.
+// Wrap returns handlers unchanged when it is false.
+var enabled bool
+
+// Setup installs the global W3C Trace Context propagator and, unless the
+// exporter is ExporterNone, a tracer provider exporting with cfg.Exporter.
+// Sampling follows OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG and
+// defaults to sampling every trace. The returned function flushes and stops
+// the exporter.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(cfg.Exporter) {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		exporter, err = newFileExporter(ctx, cfg.File)
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("invalid traces exporter %q: must be none, stdout, file or otlp", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", cfg.ServiceName),
+			attribute.String("service.version", cfg.ServiceVersion),
+		),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	enabled = true
+	return provider.Shutdown, nil
+}
+
+// newFileExporter returns an exporter appending each batch of spans to path
+// as one OTLP JSON line, the format read by the OpenTelemetry Collector's
+// otlpjsonfile receiver.
+func newFileExporter(ctx context.Context, path string) (sdktrace.SpanExporter, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no file configured")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return otlptrace.New(ctx, &fileClient{f: f})
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Suppress returns a context under which new spans are not sampled, for
+// bulk operations such as exports that would otherwise record a span per
+// region. It relies on the default parent-based sampler.
+func Suppress(ctx context.Context) context.Context {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx
+	}
+	return trace.ContextWithSpanContext(ctx, sc.WithTraceFlags(sc.TraceFlags().WithSampled(false)))
+}
+
+// String returns a string attribute holding a copy of value. Strings read
+// from a Fiber request point into buffers that are reused once it
+// completes, while spans are exported later.
+func String(key, value string) attribute.KeyValue {
+	return attribute.String(key, strings.Clone(value))
+}
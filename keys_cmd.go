@@ -0,0 +1,242 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ikhsanfalakh/geo-id/internal/config"
+	"github.com/ikhsanfalakh/geo-id/internal/middleware"
+)
+
+// generatedKeyBytes is the entropy of keys made by "keys generate".
+const generatedKeyBytes = 24
+
+// Key statuses reported by the keys command.
+const (
+	keyActive   = "active"
+	keyExpired  = "expired"
+	keyDisabled = "disabled"
+	keyUnknown  = "unknown"
+)
+
+// keyInfo describes an API key. ID is logged as api_key by the access log;
+// Key and KeySHA256 are only set for newly generated keys. RateLimit is the
+// effective limit per minute.
+type keyInfo struct {
+	ID             string     `json:"id"`
+	Key            string     `json:"key,omitempty"`
+	KeySHA256      string     `json:"key_sha256,omitempty"`
+	Masked         string     `json:"masked,omitempty"`
+	Owner          string     `json:"owner,omitempty"`
+	RateLimit      int        `json:"rate_limit,omitempty"`
+	AllowedOrigins []string   `json:"allowed_origins,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	Valid          *bool      `json:"valid,omitempty"`
+}
+
+// runKeys implements the "keys" command for API key management:
+//
+//	geo-id keys generate [-n N] [-prefix P] [-json]  make new random keys
+//	geo-id keys list [-json] [config flags]          list configured keys
+//	geo-id keys check [-json] [config flags] KEY     check a key is usable
+//
+// Keys are configured by API_KEYS or auth.api_keys, and with their owner,
+// limit and validity in the API_KEYS_FILE keys file; generated keys must be
+// added there, the file taking their key_sha256, before the server accepts
+// them. check exits with 1 for an unknown, expired or disabled key.
+func runKeys(args []string) int {
+	fs := newFlagSet("keys")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	n := fs.Int("n", 1, "generate: number of keys")
+	prefix := fs.String("prefix", "", "generate: prefix of the keys, e.g. \"partner_\"")
+	if len(args) == 0 || isHelpFlag(args[0]) {
+		out := os.Stderr
+		if len(args) > 0 {
+			out = os.Stdout
+		}
+		fs.SetOutput(out)
+		fs.Usage()
+		if len(args) > 0 {
+			return exitOK
+		}
+		return exitUsage
+	}
+	action := args[0]
+	cfg, code := loadConfig(fs, args[1:])
+	if cfg == nil {
+		return code
+	}
+
+	var keys []keyInfo
+	switch action {
+	case "generate":
+		if !noArgs(fs) {
+			return exitUsage
+		}
+		if *n < 1 {
+			fmt.Fprintln(os.Stderr, "keys: -n must be positive")
+			return exitUsage
+		}
+		for i := 0; i < *n; i++ {
+			buf := make([]byte, generatedKeyBytes)
+			if _, err := rand.Read(buf); err != nil {
+				fmt.Fprintln(os.Stderr, "keys:", err)
+				return exitFailure
+			}
+			key := *prefix + hex.EncodeToString(buf)
+			sum := sha256.Sum256([]byte(key))
+			keys = append(keys, keyInfo{ID: middleware.HashAPIKey(key), Key: key, KeySHA256: hex.EncodeToString(sum[:])})
+		}
+	case "list":
+		if !noArgs(fs) {
+			return exitUsage
+		}
+		svc, err := newAPIKeyService(cfg.Auth)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "keys:", err)
+			return exitFailure
+		}
+		now := time.Now()
+		for _, key := range svc.Keys() {
+			keys = append(keys, describeKey(key, key.Status(now), cfg.RateLimit.APIKey))
+		}
+	case "check":
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "keys: check takes exactly one key")
+			return exitUsage
+		}
+		svc, err := newAPIKeyService(cfg.Auth)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "keys:", err)
+			return exitFailure
+		}
+		key, err := svc.Lookup(fs.Arg(0))
+		info := keyInfo{ID: middleware.HashAPIKey(fs.Arg(0)), Status: keyUnknown}
+		if !errors.Is(err, middleware.ErrInvalidAPIKey) {
+			info = describeKey(key, err, cfg.RateLimit.APIKey)
+		}
+		valid := err == nil
+		info.Masked, info.Valid = maskKey(fs.Arg(0)), &valid
+		keys = append(keys, info)
+	default:
+		fmt.Fprintf(os.Stderr, "keys: unknown action %q\n", action)
+		fs.Usage()
+		return exitUsage
+	}
+
+	if *asJSON {
+		if keys == nil {
+			keys = []keyInfo{}
+		}
+		if err := printJSON(keys); err != nil {
+			return exitFailure
+		}
+	} else {
+		printKeys(action, keys)
+	}
+	if action == "check" && !*keys[0].Valid {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// printKeys writes the keys as a table.
+func printKeys(action string, keys []keyInfo) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	switch action {
+	case "generate":
+		fmt.Fprintln(tw, "ID\tKEY\tKEY_SHA256")
+		for _, k := range keys {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", k.ID, k.Key, k.KeySHA256)
+		}
+	case "list":
+		fmt.Fprintln(tw, "ID\tOWNER\tLIMIT\tSTATUS\tEXPIRES")
+		for _, k := range keys {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", k.ID, orDash(k.Owner), k.RateLimit, k.Status, formatDate(k.ExpiresAt))
+		}
+	case "check":
+		k := keys[0]
+		limit := "-"
+		if k.RateLimit > 0 {
+			limit = strconv.Itoa(k.RateLimit) + "/min"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", k.ID, k.Masked, k.Status, limit)
+	}
+	tw.Flush()
+}
+
+// newAPIKeyService loads the keys of API_KEYS and the API_KEYS_FILE keys
+// file.
+func newAPIKeyService(auth config.Auth) (*middleware.APIKeyService, error) {
+	keys := middleware.APIKeysFromList(auth.APIKeys)
+	if auth.KeysFile != "" {
+		fileKeys, err := middleware.LoadAPIKeys(auth.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+	return middleware.NewAPIKeyService(keys)
+}
+
+// describeKey returns the keyInfo of a configured key whose status is
+// status, as returned by APIKey.Status, with its effective rate limit.
+func describeKey(key middleware.APIKey, status error, defaultLimit int) keyInfo {
+	info := keyInfo{
+		ID:             key.ID,
+		Owner:          key.Owner,
+		RateLimit:      key.RateLimit,
+		AllowedOrigins: key.AllowedOrigins,
+		Status:         keyActive,
+	}
+	if info.RateLimit == 0 {
+		info.RateLimit = defaultLimit
+	}
+	if !key.CreatedAt.IsZero() {
+		info.CreatedAt = &key.CreatedAt
+	}
+	if !key.ExpiresAt.IsZero() {
+		info.ExpiresAt = &key.ExpiresAt
+	}
+	switch {
+	case errors.Is(status, middleware.ErrAPIKeyDisabled):
+		info.Status = keyDisabled
+	case errors.Is(status, middleware.ErrAPIKeyExpired):
+		info.Status = keyExpired
+	}
+	return info
+}
+
+// formatDate formats an optional date as YYYY-MM-DD, or "-" when unset.
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.DateOnly)
+}
+
+// orDash returns s, or "-" when it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// maskKey hides all but the first characters of long keys.
+func maskKey(key string) string {
+	if len(key) < 16 {
+		return strings.Repeat("*", len(key))
+	}
+	return key[:4] + strings.Repeat("*", len(key)-4)
+}
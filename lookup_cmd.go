@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+// defaultLookupLimit caps the matches of a name search.
+const defaultLookupLimit = 20
+
+// runLookup implements the "lookup" command, which queries DATA_DIR
+// offline. Arguments that are all region codes are looked up by code;
+// otherwise they are joined into a name to search for. Each result carries
+// its ancestors. It exits with 1 when a code is not found or no name
+// matches.
+//
+//	geo-id lookup [-level LEVEL] [-limit N] [-json] [config flags] CODE... | NAME
+func runLookup(args []string) int {
+	fs := newFlagSet("lookup")
+	level := fs.String("level", "", "only search regions of this level: state, city, district or village")
+	limit := fs.Int("limit", defaultLookupLimit, "maximum number of name matches")
+	asJSON := fs.Bool("json", false, "print the results as JSON")
+	cfg, code := loadConfig(fs, args)
+	if cfg == nil {
+		return code
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	switch service.Level(*level) {
+	case "", service.LevelState, service.LevelCity, service.LevelDistrict, service.LevelVillage:
+	default:
+		fmt.Fprintf(os.Stderr, "lookup: invalid level %q\n", *level)
+		return exitUsage
+	}
+
+	ctx := context.Background()
+	svc := service.NewLocationService(cfg.Data.Dir)
+	codes := fs.Args()
+	for _, arg := range codes {
+		if _, err := service.LevelOf(arg); err != nil {
+			// Not a code: search by name
+			matches, err := svc.Search(ctx, strings.Join(fs.Args(), " "), service.Level(*level), *limit)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "lookup:", err)
+				return exitFailure
+			}
+			codes = make([]string, len(matches))
+			for i, m := range matches {
+				codes[i] = m.Code
+			}
+			break
+		}
+	}
+
+	items, err := svc.BatchLookup(ctx, codes, true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lookup:", err)
+		return exitFailure
+	}
+	if *asJSON {
+		if err := printJSON(items); err != nil {
+			return exitFailure
+		}
+	} else {
+		printLookup(items)
+	}
+
+	if len(items) == 0 {
+		fmt.Fprintf(os.Stderr, "lookup: no region matches %q\n", strings.Join(fs.Args(), " "))
+		return exitFailure
+	}
+	for _, item := range items {
+		if item.Error != nil {
+			return exitFailure
+		}
+	}
+	return exitOK
+}
+
+// printLookup writes lookup results as a table, with errors on stderr.
+func printLookup(items []model.BatchItem) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	found := false
+	for _, item := range items {
+		if item.Error != nil {
+			continue
+		}
+		if !found {
+			fmt.Fprintln(tw, "CODE\tLEVEL\tTYPE\tNAME\tPARENTS")
+			found = true
+		}
+		parents := make([]string, len(item.Ancestors))
+		for i, a := range item.Ancestors {
+			parents[len(parents)-1-i] = a.Value
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", item.Code, item.Level,
+			service.RegionType(*item.Region), item.Region.Value, strings.Join(parents, ", "))
+	}
+	tw.Flush()
+	for _, item := range items {
+		if item.Error != nil {
+			fmt.Fprintf(os.Stderr, "lookup: %s: %s\n", item.Code, item.Error.Message)
+		}
+	}
+}
@@ -1,23 +1,46 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/gofiber/swagger"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
 
 	"github.com/ikhsanfalakh/geo-id/docs"
+	"github.com/ikhsanfalakh/geo-id/internal/config"
+	"github.com/ikhsanfalakh/geo-id/internal/grpcserver"
 	"github.com/ikhsanfalakh/geo-id/internal/handler"
+	"github.com/ikhsanfalakh/geo-id/internal/health"
+	"github.com/ikhsanfalakh/geo-id/internal/logging"
+	"github.com/ikhsanfalakh/geo-id/internal/metrics"
 	"github.com/ikhsanfalakh/geo-id/internal/middleware"
-	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
 	"github.com/ikhsanfalakh/geo-id/internal/service"
+	"github.com/ikhsanfalakh/geo-id/internal/tracing"
 )
 
+// dotenvErr records why no .env file was loaded, logged once logging is
+// configured.
+var dotenvErr error
+
 // @title Geo-ID API
 // @version 1.0
 // @description API for Indonesian Administrative Regions (Provinces, Cities, Districts, Villages)
@@ -31,47 +54,109 @@ import (
 // @tag.description Operations regarding districts
 // @tag.name villages
 // @tag.description Operations regarding villages
+// @tag.name regions
+// @tag.description Operations on regions of any level
+// @tag.name health
+// @tag.description Liveness and readiness probes
 func main() {
 	// Load .env file if it exists (ignore error if file doesn't exist)
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables or defaults")
+	dotenvErr = godotenv.Load()
+	os.Exit(dispatch(os.Args[1:]))
+}
+
+// runServe implements the "serve" command, which starts the HTTP, gRPC and
+// admin servers and runs until SIGINT or SIGTERM.
+//
+//	geo-id [serve] [flags]
+func runServe(args []string) int {
+	// Configuration from the config file, environment and flags
+	fs := flag.NewFlagSet("geo-id serve", flag.ContinueOnError)
+	cfg, code := loadConfig(fs, args)
+	if cfg == nil {
+		return code
 	}
+	if !noArgs(fs) {
+		return exitUsage
+	}
+	if dotenvErr != nil {
+		slog.Debug("No .env file found, using environment variables or defaults")
+	}
+	appName, appVersion := cfg.App.Name, cfg.App.Version
 
-	// Get configuration from environment variables with defaults
-	appName := getEnv("APP_NAME", "Geo-ID API")
-	appVersion := getEnv("APP_VERSION", "1.0")
-	env := getEnv("ENV", "development")
-	enableSwagger := getEnvAsBool("ENABLE_SWAGGER", true)
+	// OpenTelemetry tracing (OTEL_TRACES_EXPORTER: none, stdout, file, otlp).
+	// traceparent headers are honoured even when no exporter is set.
+	stopTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:       cfg.Tracing.Exporter,
+		File:           cfg.Tracing.File,
+		ServiceName:    "geo-id",
+		ServiceVersion: appVersion,
+	})
+	if err != nil {
+		fatal("Failed to set up tracing", "error", err)
+	}
+	if cfg.Tracing.Exporter != tracing.ExporterNone {
+		slog.Info("Tracing enabled", "exporter", cfg.Tracing.Exporter)
+	}
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
-		AppName: appName + " v" + appVersion,
+		AppName:      appName + " v" + appVersion,
+		ErrorHandler: handler.ErrorHandler,
+		// The banner would break up JSON logs
+		DisableStartupMessage: cfg.Log.Format == logging.FormatJSON,
 	})
 
-	// Get data directory
-	dataDir := getEnv("DATA_DIR", "")
-	if dataDir == "" {
-		cwd, _ := os.Getwd()
-		dataDir = filepath.Join(cwd, "data")
+	// Request IDs, traces and access logs wrap every other middleware
+	app.Use(requestid.New())
+	app.Use(tracing.Middleware())
+	if cfg.Log.Access {
+		app.Use(middleware.AccessLog(slog.Default()))
 	}
 
+	dataDir := cfg.Data.Dir
+
 	// Initialize API key service & rate limiter middleware
-	apiKeySvc := middleware.NewAPIKeyService()
-	limitAnon := getEnvAsInt("RATE_LIMIT_ANONYMOUS", middleware.DefaultLimitAnonymous)
-	limitKey := getEnvAsInt("RATE_LIMIT_API_KEY", middleware.DefaultLimitAPIKey)
-	rateLimitCfg := middleware.NewRateLimitConfig(apiKeySvc, limitAnon, limitKey)
-	app.Use(middleware.RateLimitMiddleware(rateLimitCfg))
-	log.Printf("Rate limiting enabled: anonymous=%d req/min, api_key=%d req/min", limitAnon, limitKey)
-
-	// Initialize service and handler
+	apiKeySvc, err := newAPIKeyService(cfg.Auth)
+	if err != nil {
+		fatal("Failed to load API keys", "error", err)
+	}
+	limitAnon, limitKey := cfg.RateLimit.Anonymous, cfg.RateLimit.APIKey
+	rateLimitCfg, redisClient, err := newRateLimitConfig(cfg.RateLimit, apiKeySvc)
+	if err != nil {
+		fatal("Invalid rate limit configuration", "error", err)
+	}
+	rateLimitCfg.Cost = handler.RequestCost
+	app.Use(metrics.Middleware())
+
+	// CORS for browser clients (no allowed origins disables it).
+	// Preflights are answered here, before the rate limiter.
+	if origins := cfg.CORS.AllowedOrigins; len(origins) > 0 {
+		corsHandler, err := middleware.CORS(cfg.CORS.Middleware())
+		if err != nil {
+			fatal("Invalid CORS configuration", "error", err)
+		}
+		app.Use(corsHandler)
+		slog.Info("CORS enabled", "origins", origins)
+	}
+
+	app.Use(tracing.Wrap("middleware.ratelimit", middleware.RateLimitMiddleware(rateLimitCfg)))
+	slog.Info("Rate limiting enabled", "anonymous_per_min", limitAnon, "api_key_per_min", limitKey,
+		"algorithm", strings.ToLower(cfg.RateLimit.Algorithm), "store", strings.ToLower(cfg.RateLimit.Store),
+		"fail_open", cfg.RateLimit.FailOpen)
+
+	// Initialize service and handler. The dataset is loaded in the
+	// background once the server is listening (see prepare).
 	svc := service.NewLocationService(dataDir)
+	svc.PastDataDirs = cfg.Data.PastDirs
 	h := handler.NewLocationHandler(svc)
+	gh, err := handler.NewGraphQLHandler(svc)
+	if err != nil {
+		fatal("Failed to build GraphQL schema", "error", err)
+	}
 
 	// Configure Swagger host dynamically based on BASE_URL
-	port := getEnv("PORT", "8080")
-	baseURL := getEnv("BASE_URL", "localhost:"+port)
-
-	swaggerHost := strings.TrimPrefix(baseURL, "http://")
+	port := strconv.Itoa(cfg.Server.Port)
+	swaggerHost := strings.TrimPrefix(cfg.App.BaseURL, "http://")
 	swaggerHost = strings.TrimPrefix(swaggerHost, "https://")
 
 	if parts := strings.SplitN(swaggerHost, "/", 2); len(parts) == 2 {
@@ -85,7 +170,7 @@ func main() {
 	app.Static("/assets", "./docs/assets")
 
 	// Swagger route (conditionally enabled)
-	if enableSwagger {
+	if cfg.App.Swagger {
 		app.Get("/apidocs/*", swagger.New(swagger.Config{
 			CustomStyle: `
 			.swagger-ui .topbar { background-color: #1b1b1b; }
@@ -113,62 +198,254 @@ func main() {
 			.swagger-ui .topbar .download-url-wrapper { display: none; } 
 		`,
 		}))
-		log.Printf("Swagger UI enabled at /apidocs/index.html")
+		slog.Info("Swagger UI enabled", "path", "/apidocs/index.html")
 	} else {
-		log.Printf("Swagger UI is disabled (ENV=%s)", env)
+		slog.Info("Swagger UI is disabled", "env", cfg.App.Env)
 	}
 
-	// Register routes
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(model.NewSuccessResponse(fiber.Map{
-			"app":      appName,
-			"version":  appVersion,
-			"docs_url": "/apidocs/index.html",
-			"message":  "Welcome to Geo-ID API",
-		}))
-	})
+	// Prometheus metrics on the admin listener (ADMIN_PORT=0 serves them
+	// on the main port instead)
+	var adminSrv *http.Server
+	if cfg.Server.AdminPort != 0 {
+		adminPort := strconv.Itoa(cfg.Server.AdminPort)
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		adminSrv = &http.Server{Addr: ":" + adminPort, Handler: mux}
+		go func() {
+			if err := adminSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Admin server stopped", "error", err)
+			}
+		}()
+		slog.Info("Metrics available", "port", adminPort, "path", "/metrics")
+	} else {
+		app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+	}
 
-	app.Get("/states", h.GetStates)
-	app.Get("/states/:id", h.GetState)
-	app.Get("/states/:id/cities", h.GetCities)
+	// Health probes answer while the dataset is still loading; every
+	// route registered after the gate waits for it. The gate stays open
+	// while shutting down, when only the readiness probe fails.
+	tracker := health.NewTracker()
+	hh := handler.NewHealthHandler(tracker)
+	app.Get("/actuator/health", hh.Readiness)
+	app.Get("/actuator/health/liveness", hh.Liveness)
+	app.Get("/actuator/health/readiness", hh.Readiness)
+	app.Use(tracing.Wrap("middleware.ready", middleware.RequireReady(tracker.Loaded)))
 
-	app.Get("/cities/:id", h.GetCity)
-	app.Get("/cities/:id/districts", h.GetDistricts)
+	// HTTP caching per route group: validators derive from the dataset
+	// edition and the response format (the export's own for /export), and
+	// max-age is configurable (0 sends no-cache)
+	regionCache := tracing.Wrap("middleware.cache", middleware.HTTPCache(svc, cfg.Cache.MaxAgeRegions, render.Negotiate))
+	exportCache := tracing.Wrap("middleware.cache", middleware.HTTPCache(svc, cfg.Cache.MaxAgeExport, handler.ExportFormat))
 
-	app.Get("/districts/:id", h.GetDistrict)
-	app.Get("/districts/:id/villages", h.GetVillages)
+	// Response compression (br, zstd, gzip). Full region lists are
+	// compressed once while loading and served from memory.
+	compressMinSize := cfg.Compress.MinSize
+	var precompressed *middleware.Precompressed
+	if cfg.Compress.Precompress {
+		precompressed = middleware.NewPrecompressed()
+	}
+	listCompress := tracing.Wrap("middleware.compress", middleware.Compress(precompressed, compressMinSize))
+	dynamicCompress := tracing.Wrap("middleware.compress", middleware.Compress(nil, compressMinSize))
+
+	// Register routes under every API version prefix: v1 (also served
+	// unprefixed, frozen for existing clients) and v2
+	routes := handler.Routes{
+		AppName:         appName,
+		AppVersion:      appVersion,
+		Locations:       h,
+		GraphQL:         gh,
+		RegionCache:     regionCache,
+		ExportCache:     exportCache,
+		ListCompress:    listCompress,
+		DynamicCompress: dynamicCompress,
+	}
+	for _, version := range render.Versions {
+		for _, prefix := range version.Prefixes() {
+			routes.Register(app.Group(prefix))
+		}
+	}
+	app.Use(handler.NotFound)
 
-	app.Get("/villages/:id", h.GetVillage)
+	// Load the dataset, then start gRPC on its own port (GRPC_PORT=0
+	// disables it)
+	grpcPort := strconv.Itoa(cfg.Server.GRPCPort)
+	var grpcSrv *grpc.Server
+	if cfg.Server.GRPCPort != 0 {
+		grpcSrv = grpcserver.New(svc, rateLimitCfg)
+	}
+	go func() {
+		if err := prepare(svc, h, precompressed, tracker); err != nil {
+			fatal("Failed to load data", "data_dir", dataDir, "error", err)
+		}
+		if grpcSrv == nil {
+			return
+		}
+		lis, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			fatal("Failed to listen for gRPC", "port", grpcPort, "error", err)
+		}
+		slog.Info("gRPC server listening", "port", grpcPort)
+		if err := grpcSrv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			slog.Error("gRPC server stopped", "error", err)
+		}
+	}()
 
-	// Start server
-	log.Printf("Starting %s v%s on port %s (ENV=%s)", appName, appVersion, port, env)
-	if err := app.Listen(":" + port); err != nil {
-		panic(err)
+	// Start server and serve until SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":" + port)
+	}()
+	slog.Info("Starting server", "app", appName, "version", appVersion, "port", port, "env", cfg.App.Env)
+	select {
+	case err := <-listenErr:
+		fatal("Server stopped", "error", err)
+	case <-ctx.Done():
 	}
+	// A second signal exits immediately
+	stop()
+
+	slog.Info("Shutting down", "timeout", cfg.Server.ShutdownTimeout.String())
+	err = shutdown{
+		tracker:   tracker,
+		delay:     cfg.Server.ShutdownDelay,
+		timeout:   cfg.Server.ShutdownTimeout,
+		app:       app,
+		grpc:      grpcSrv,
+		admin:     adminSrv,
+		rateLimit: rateLimitCfg,
+		redis:     redisClient,
+		tracing:   stopTracing,
+	}.run()
+	if err != nil {
+		fatal("Shutdown incomplete", "error", err)
+	}
+	slog.Info("Server stopped")
+	return exitOK
+}
+
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
 
-// getEnv retrieves an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// newRateLimitConfig builds the rate limiters of both tiers in the
+// configured store. The Redis client of the redis store is returned for the
+// caller to close; it is nil for the memory store.
+func newRateLimitConfig(cfg config.RateLimit, apiKeySvc *middleware.APIKeyService) (*middleware.RateLimitConfig, *redis.Client, error) {
+	if strings.ToLower(cfg.Store) != config.LimiterRedis {
+		algorithm := strings.ToLower(cfg.Algorithm)
+		anonymous := newMemoryLimiter(algorithm, cfg.Anonymous)
+		apiKey := newMemoryLimiter(algorithm, cfg.APIKey)
+		sizes := []func() int{apiKey.Len}
+		keyLimiters := make(map[int]middleware.Limiter)
+		for _, limit := range apiKeySvc.RateLimits() {
+			limiter := newMemoryLimiter(algorithm, limit)
+			keyLimiters[limit] = limiter
+			sizes = append(sizes, limiter.Len)
+		}
+		metrics.TrackLimiter(metrics.TierAnonymous, anonymous.Len)
+		metrics.TrackLimiter(metrics.TierAPIKey, func() int {
+			n := 0
+			for _, size := range sizes {
+				n += size()
+			}
+			return n
+		})
+		return &middleware.RateLimitConfig{
+			APIKeyService:    apiKeySvc,
+			AnonymousLimiter: anonymous,
+			APIKeyLimiter:    apiKey,
+			KeyLimiters:      keyLimiters,
+		}, nil, nil
+	}
+
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rate_limit.redis_url: %w", err)
+	}
+	client := redis.NewClient(opts)
+	// The server may come up later, so an unreachable one is not fatal;
+	// until then requests fail open or closed as configured.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		slog.Warn("Redis rate limit store unreachable", "addr", opts.Addr, "error", err)
+	}
+
+	keyPrefix := cfg.RedisPrefix + metrics.TierAPIKey + ":"
+	keyLimiters := make(map[int]middleware.Limiter)
+	for _, limit := range apiKeySvc.RateLimits() {
+		keyLimiters[limit] = middleware.NewRedisLimiter(client, keyPrefix, limit, middleware.RateLimitWindow)
 	}
-	return defaultValue
+	return &middleware.RateLimitConfig{
+		APIKeyService:    apiKeySvc,
+		AnonymousLimiter: middleware.NewRedisLimiter(client, cfg.RedisPrefix+metrics.TierAnonymous+":", cfg.Anonymous, middleware.RateLimitWindow),
+		APIKeyLimiter:    middleware.NewRedisLimiter(client, keyPrefix, cfg.APIKey, middleware.RateLimitWindow),
+		KeyLimiters:      keyLimiters,
+		FailOpen:         cfg.FailOpen,
+	}, client, nil
+}
+
+// memoryLimiter is an in-memory limiter, which can count its identifiers.
+type memoryLimiter interface {
+	middleware.Limiter
+	Len() int
 }
 
-// getEnvAsBool retrieves an environment variable as a boolean or returns a default value
-func getEnvAsBool(key string, defaultValue bool) bool {
-	valStr := getEnv(key, "")
-	if val, err := strconv.ParseBool(valStr); err == nil {
-		return val
+// newMemoryLimiter returns an in-memory limiter of the algorithm.
+func newMemoryLimiter(algorithm string, limit int) memoryLimiter {
+	var limiter memoryLimiter
+	switch algorithm {
+	case config.AlgorithmGCRA:
+		limiter = middleware.NewGCRALimiter(limit, middleware.RateLimitWindow)
+	case config.AlgorithmSlidingWindow:
+		limiter = middleware.NewSlidingWindowLimiter(limit, middleware.RateLimitWindow)
+	default:
+		limiter = middleware.NewRateLimiter(limit, middleware.RateLimitWindow)
 	}
-	return defaultValue
+	return limiter
 }
 
-// getEnvAsInt retrieves an environment variable as an integer or returns a default value
-func getEnvAsInt(key string, defaultValue int) int {
-	valStr := getEnv(key, "")
-	if val, err := strconv.Atoi(valStr); err == nil && val > 0 {
-		return val
+// prepare loads and validates the dataset, precompresses the region lists
+// when store is non-nil, and marks the server ready
+func prepare(svc *service.LocationService, h *handler.LocationHandler, store *middleware.Precompressed, tracker *health.Tracker) (err error) {
+	ctx, span := otel.Tracer("github.com/ikhsanfalakh/geo-id").Start(context.Background(), "dataset.prepare")
+	defer func() { tracing.End(span, err) }()
+
+	start := time.Now()
+	orphans, err := svc.Validate()
+	if err != nil {
+		return err
+	}
+	if orphans > 0 {
+		slog.Warn("Dataset lists villages under districts it does not contain", "villages", orphans)
+	}
+	edition, err := svc.Edition()
+	if err != nil {
+		return err
+	}
+	counts, err := svc.Counts()
+	if err != nil {
+		return err
+	}
+	if store != nil {
+		if err := h.Precompress(tracing.Suppress(ctx), store); err != nil {
+			return fmt.Errorf("precompress region lists: %w", err)
+		}
+		metrics.SetPrecompressedBytes(store.Sizes())
+		slog.Info("Precompressed region lists", "routes", store.Len(), "bytes", store.Sizes())
 	}
-	return defaultValue
+	metrics.SetDataset(*edition, counts, time.Since(start))
+	tracker.SetReady(*edition, counts, time.Since(start))
+	slog.Info("Dataset ready",
+		"edition", edition.Name,
+		"duration", time.Since(start).Round(time.Millisecond).String(),
+		"states", counts.States,
+		"cities", counts.Cities,
+		"districts", counts.Districts,
+		"villages", counts.Villages)
+	return nil
 }
@@ -0,0 +1,250 @@
+// Package client is a Go client for the geo-id HTTP API. It offers typed
+// methods for every endpoint, sends an API key when configured, retries
+// rate-limited requests once the limit resets, pages through lists with
+// iterators and maps error responses to *Error values.
+//
+//	c := client.New("https://geo.example.com", client.WithAPIKey(os.Getenv("GEO_ID_API_KEY")))
+//	city, err := c.GetCity(ctx, "32.73", nil)
+//	if errors.Is(err, client.ErrNotFound) {
+//		// ...
+//	}
+//	for district, err := range c.Districts(ctx, "32.73", nil) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(district.Code, district.Name)
+//	}
+//
+// The client uses the /v2 API. A Client is safe for concurrent use.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
+)
+
+const (
+	// DefaultMaxRetries is how often a rate-limited request is retried.
+	DefaultMaxRetries = 3
+	// DefaultMaxRetryWait is the longest the client waits before a retry;
+	// requests that would need a longer wait fail with the 429 error.
+	DefaultMaxRetryWait = 2 * time.Minute
+
+	// apiPrefix is the API version the client speaks.
+	apiPrefix = "/v2"
+)
+
+// Client calls the geo-id API.
+type Client struct {
+	baseURL      string
+	apiKey       string
+	userAgent    string
+	httpClient   *http.Client
+	maxRetries   int
+	maxRetryWait time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAPIKey sends key in the X-API-KEY header of every request, for the
+// API key rate limit tier.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithHTTPClient makes requests with hc instead of http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithRetries sets how often a request answered with 429 Too Many Requests,
+// or 503 Service Unavailable with Retry-After, is retried, and the longest
+// wait before a retry. Zero retries disables retrying.
+func WithRetries(retries int, maxWait time.Duration) Option {
+	return func(c *Client) { c.maxRetries, c.maxRetryWait = retries, maxWait }
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// New returns a client for the API at baseURL, the root of the server such
+// as "https://geo.example.com".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		userAgent:    "geo-id-go-client/" + geoid.Version,
+		httpClient:   http.DefaultClient,
+		maxRetries:   DefaultMaxRetries,
+		maxRetryWait: DefaultMaxRetryWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// request describes one API call.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	accept string
+	// graphQL passes 400 responses, which carry GraphQL errors, through to
+	// the caller.
+	graphQL bool
+}
+
+// do sends req, retrying rate-limited attempts, and returns the response
+// of a successful one. Error responses are returned as *Error.
+func (c *Client) do(ctx context.Context, req request) (*http.Response, error) {
+	u := c.baseURL + apiPrefix + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		accept := req.accept
+		if accept == "" {
+			accept = "application/json"
+		}
+		httpReq.Header.Set("Accept", accept)
+		httpReq.Header.Set("User-Agent", c.userAgent)
+		if body != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+		if c.apiKey != "" {
+			httpReq.Header.Set("X-API-KEY", c.apiKey)
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 400 || (req.graphQL && resp.StatusCode == http.StatusBadRequest) {
+			return resp, nil
+		}
+
+		apiErr := parseError(resp)
+		wait, ok := c.retryWait(resp, attempt)
+		if !ok {
+			return nil, apiErr
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(ctx.Err(), apiErr)
+		case <-timer.C:
+		}
+	}
+}
+
+// retryWait returns how long to wait before retrying a failed attempt, and
+// whether to retry at all. Rate-limited requests wait until
+// X-RateLimit-Reset, falling back to Retry-After and then to exponential
+// backoff; unavailable servers are only retried when they send
+// Retry-After.
+func (c *Client) retryWait(resp *http.Response, attempt int) (time.Duration, bool) {
+	if attempt >= c.maxRetries {
+		return 0, false
+	}
+	var wait time.Duration
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		if reset, ok := resetTime(resp.Header); ok {
+			// The header has second precision, so wait out the second it
+			// names.
+			wait = time.Until(reset.Add(time.Second))
+		} else if after, ok := retryAfter(resp.Header); ok {
+			wait = after
+		} else {
+			wait = time.Second << attempt
+		}
+	case http.StatusServiceUnavailable:
+		after, ok := retryAfter(resp.Header)
+		if !ok {
+			return 0, false
+		}
+		wait = after
+	default:
+		return 0, false
+	}
+	if wait > c.maxRetryWait {
+		return 0, false
+	}
+	return max(wait, 0), true
+}
+
+// resetTime parses the X-RateLimit-Reset header, a Unix timestamp.
+func resetTime(h http.Header) (time.Time, bool) {
+	sec, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0), true
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	sec, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || sec < 0 {
+		return 0, false
+	}
+	return time.Duration(sec) * time.Second, true
+}
+
+// envelope is the body of a successful /v2 response.
+type envelope struct {
+	Data       json.RawMessage `json:"data"`
+	Pagination *Pagination     `json:"pagination"`
+}
+
+// getJSON sends req and decodes the data of the response envelope into
+// out, returning the pagination of list responses.
+func (c *Client) getJSON(ctx context.Context, req request, out interface{}) (*Pagination, error) {
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("decoding %s response: %w", req.path, err)
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return nil, fmt.Errorf("decoding %s response: %w", req.path, err)
+	}
+	return env.Pagination, nil
+}
+
+// discard drains and closes a response body so the connection is reused.
+func discard(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, 64<<10))
+	body.Close()
+}
@@ -0,0 +1,405 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+
+	"github.com/ikhsanfalakh/geo-id/internal/handler"
+	"github.com/ikhsanfalakh/geo-id/internal/middleware"
+	"github.com/ikhsanfalakh/geo-id/internal/render"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+	"github.com/ikhsanfalakh/geo-id/pkg/client"
+)
+
+var (
+	loadOnce sync.Once
+	svc      *service.LocationService
+	loadErr  error
+)
+
+// loadService returns the service on the repository's data directory,
+// loaded once for every test.
+func loadService(t *testing.T) *service.LocationService {
+	t.Helper()
+	loadOnce.Do(func() {
+		svc = service.NewLocationService("../../data")
+		loadErr = svc.Load()
+	})
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+	return svc
+}
+
+// server is the API mounted on httptest.NewServer with the server's routes,
+// counting the requests it receives.
+type server struct {
+	*httptest.Server
+	requests atomic.Int64
+}
+
+// newServer starts the API with the given rate limits and an API key
+// "test-key". rewrite, if set, changes request paths before routing.
+func newServer(t *testing.T, anonymous middleware.Limiter, rewrite func(path string) string) *server {
+	t.Helper()
+	svc := loadService(t)
+	gh, err := handler.NewGraphQLHandler(svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := middleware.NewAPIKeyService(middleware.APIKeysFromList([]string{"test-key"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := &middleware.RateLimitConfig{
+		APIKeyService:    keys,
+		AnonymousLimiter: anonymous,
+		APIKeyLimiter:    middleware.NewRateLimiter(middleware.DefaultLimitAPIKey, middleware.RateLimitWindow),
+		Cost:             handler.RequestCost,
+	}
+	t.Cleanup(limits.Stop)
+
+	app := fiber.New(fiber.Config{ErrorHandler: handler.ErrorHandler})
+	app.Use(requestid.New())
+	app.Use(middleware.RateLimitMiddleware(limits))
+	routes := handler.Routes{
+		AppName:         "geo-id",
+		AppVersion:      "test",
+		Locations:       handler.NewLocationHandler(svc),
+		GraphQL:         gh,
+		RegionCache:     middleware.HTTPCache(svc, 0, render.Negotiate),
+		ExportCache:     middleware.HTTPCache(svc, 0, handler.ExportFormat),
+		ListCompress:    middleware.Compress(nil, 1024),
+		DynamicCompress: middleware.Compress(nil, 1024),
+	}
+	for _, version := range render.Versions {
+		for _, prefix := range version.Prefixes() {
+			routes.Register(app.Group(prefix))
+		}
+	}
+	app.Use(handler.NotFound)
+
+	s := &server{}
+	api := adaptor.FiberApp(app)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if rewrite != nil {
+			r.URL.Path = rewrite(r.URL.Path)
+		}
+		api(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// unlimited is an anonymous limiter that never trips in tests.
+func unlimited() middleware.Limiter {
+	return middleware.NewRateLimiter(1_000_000, middleware.RateLimitWindow)
+}
+
+func TestClientMethods(t *testing.T) {
+	s := newServer(t, unlimited(), nil)
+	c := client.New(s.URL)
+	ctx := context.Background()
+
+	info, err := c.Info(ctx)
+	if err != nil || info.App != "geo-id" || info.Version != "test" {
+		t.Fatalf("Info: got %+v, %v", info, err)
+	}
+
+	regions := []struct {
+		name string
+		get  func() (*client.Region, error)
+		want client.Region
+	}{
+		{"GetState", func() (*client.Region, error) { return c.GetState(ctx, "32", nil) }, client.Region{Code: "32", Name: "Jawa Barat"}},
+		{"GetCity", func() (*client.Region, error) { return c.GetCity(ctx, "32.73", nil) }, client.Region{Code: "32.73", Name: "Kota Bandung"}},
+		{"GetDistrict", func() (*client.Region, error) { return c.GetDistrict(ctx, "32.73.01", nil) }, client.Region{Code: "32.73.01", Name: "Sukasari"}},
+		{"GetVillage", func() (*client.Region, error) { return c.GetVillage(ctx, "32.73.01.1001") }, client.Region{Code: "32.73.01.1001", Name: "Sukarasa"}},
+	}
+	for _, tt := range regions {
+		got, err := tt.get()
+		if err != nil || got.Code != tt.want.Code || got.Name != tt.want.Name {
+			t.Errorf("%s: got %+v, %v; want %+v", tt.name, got, err, tt.want)
+		}
+	}
+
+	district, err := c.GetDistrict(ctx, "32.73.01", &client.RegionOptions{Expand: "villages"})
+	if err != nil || len(district.Children) != 4 {
+		t.Errorf("GetDistrict with expand: got %+v, %v; want 4 villages", district, err)
+	}
+
+	lists := []struct {
+		name      string
+		list      func() (*client.Page, error)
+		wantTotal int
+		wantFirst string
+	}{
+		{"ListStates", func() (*client.Page, error) { return c.ListStates(ctx, nil) }, 38, "11"},
+		{"ListCities", func() (*client.Page, error) { return c.ListCities(ctx, "32", nil) }, 27, "32.01"},
+		{"ListDistricts", func() (*client.Page, error) { return c.ListDistricts(ctx, "32.73", nil) }, 30, "32.73.01"},
+		{"ListVillages", func() (*client.Page, error) { return c.ListVillages(ctx, "32.73.01", nil) }, 4, "32.73.01.1001"},
+		{"ListChildren", func() (*client.Page, error) { return c.ListChildren(ctx, "32.73.01", nil) }, 4, "32.73.01.1001"},
+	}
+	for _, tt := range lists {
+		page, err := tt.list()
+		if err != nil || page.Pagination.Total != tt.wantTotal || len(page.Items) != tt.wantTotal || page.Items[0].Code != tt.wantFirst {
+			t.Errorf("%s: got %+v, %v; want %d items starting at %s", tt.name, page, err, tt.wantTotal, tt.wantFirst)
+		}
+	}
+
+	page, err := c.ListVillages(ctx, "32.73.01", &client.ListOptions{Sort: "name", Query: "SA", Limit: 1})
+	if err != nil || page.Pagination.Total != 2 || len(page.Items) != 1 || page.Items[0].Name != "Sarijadi" {
+		t.Errorf("ListVillages with options: got %+v, %v; want Sarijadi of 2", page, err)
+	}
+
+	detail, err := c.GetRegion(ctx, "32.73")
+	if err != nil || detail.Level != "city" || detail.Type != "kota" || detail.ParentCode != "32" {
+		t.Errorf("GetRegion: got %+v, %v", detail, err)
+	}
+
+	tree, err := c.GetTree(ctx, "32.73", 2)
+	if err != nil || len(tree.Children) != 30 || len(tree.Children[0].Children) != 4 {
+		t.Errorf("GetTree: got %v; want 30 districts with their villages", err)
+	}
+
+	items, err := c.BatchGet(ctx, []string{"32.73", "99.99", "32.73.01.1001"}, true)
+	switch {
+	case err != nil || len(items) != 3:
+		t.Errorf("BatchGet: got %+v, %v", items, err)
+	case items[0].Region == nil || items[0].Region.Name != "Kota Bandung" || len(items[0].Ancestors) != 1:
+		t.Errorf("BatchGet: got first item %+v", items[0])
+	case items[1].Error == nil || items[1].Error.Code != "NOT_FOUND":
+		t.Errorf("BatchGet: got second item %+v, want NOT_FOUND", items[1])
+	case items[2].Level != "village" || len(items[2].Ancestors) != 3:
+		t.Errorf("BatchGet: got third item %+v", items[2])
+	}
+
+	export, err := c.Export(ctx, &client.ExportOptions{Format: client.FormatCSV, Within: "32.73.01"})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	body, err := io.ReadAll(export)
+	export.Close()
+	if err != nil || strings.Count(string(body), "\n") != 6 || export.Edition == "" {
+		t.Errorf("Export: got %q, edition %q, %v; want a header, the district and its 4 villages", body, export.Edition, err)
+	}
+
+	var records []client.ExportRecord
+	for record, err := range c.ExportRecords(ctx, &client.ExportOptions{Level: "district", Within: "32.73"}) {
+		if err != nil {
+			t.Fatalf("ExportRecords: %v", err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 30 || records[0].Code != "32.73.01" || records[0].ParentNames[1] != "Kota Bandung" {
+		t.Errorf("ExportRecords: got %d records starting with %+v", len(records), records[0])
+	}
+
+	nik, err := c.DecodeNIK(ctx, "3273014101900001")
+	if err != nil || nik.District == nil || nik.District.Name != "Sukasari" || nik.BirthDate != "1990-01-01" || nik.Gender != "female" {
+		t.Errorf("DecodeNIK: got %+v, %v", nik, err)
+	}
+
+	var data struct {
+		City struct {
+			Name   string
+			Parent struct{ Name string }
+		}
+	}
+	err = c.GraphQL(ctx, `query($code: String!) { city(code: $code) { name parent { name } } }`, map[string]any{"code": "32.73"}, &data)
+	if err != nil || data.City.Name != "Kota Bandung" || data.City.Parent.Name != "Jawa Barat" {
+		t.Errorf("GraphQL: got %+v, %v", data, err)
+	}
+	var gqlErrs client.GraphQLErrors
+	if err := c.GraphQL(ctx, `{ nope }`, nil, nil); !errors.As(err, &gqlErrs) {
+		t.Errorf("GraphQL with an invalid query: got %v, want GraphQLErrors", err)
+	}
+}
+
+func TestIteratorFollowsPages(t *testing.T) {
+	s := newServer(t, unlimited(), nil)
+	c := client.New(s.URL)
+	ctx := context.Background()
+
+	var codes []string
+	for state, err := range c.States(ctx, &client.ListOptions{Limit: 10}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		codes = append(codes, state.Code)
+	}
+	if len(codes) != 38 || codes[0] != "11" || codes[37] != "96" {
+		t.Fatalf("got %d states from %v to %v, want 38 from 11 to 96", len(codes), codes[0], codes[len(codes)-1])
+	}
+	if n := s.requests.Load(); n != 4 {
+		t.Fatalf("got %d requests, want 4 pages of 10", n)
+	}
+
+	// Stopping early fetches no further pages.
+	s.requests.Store(0)
+	for range c.States(ctx, &client.ListOptions{Limit: 10}) {
+		break
+	}
+	if n := s.requests.Load(); n != 1 {
+		t.Fatalf("got %d requests after breaking on the first item, want 1", n)
+	}
+}
+
+func TestRetryAfterRateLimitReset(t *testing.T) {
+	s := newServer(t, middleware.NewRateLimiter(2, time.Second), nil)
+	ctx := context.Background()
+
+	// Without retries, the third request within a second fails with the
+	// reset time.
+	noRetry := client.New(s.URL, client.WithRetries(0, time.Minute))
+	for range 2 {
+		if _, err := noRetry.GetState(ctx, "32", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := noRetry.GetState(ctx, "32", nil)
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, client.ErrRateLimitExceeded) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("got %v, want a 429 RATE_LIMIT_EXCEEDED error", err)
+	}
+	if wait := time.Until(apiErr.ResetAt); wait < -time.Second || wait > time.Second {
+		t.Fatalf("got reset at %v, want within the second-long window", apiErr.ResetAt)
+	}
+
+	// With retries, it waits for X-RateLimit-Reset and succeeds.
+	c := client.New(s.URL)
+	s.requests.Store(0)
+	start := time.Now()
+	state, err := c.GetState(ctx, "32", nil)
+	if err != nil || state.Name != "Jawa Barat" {
+		t.Fatalf("got %+v, %v; want the state after a retry", state, err)
+	}
+	if n := s.requests.Load(); n < 2 {
+		t.Fatalf("got %d requests, want the rate-limited one and a retry", n)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("retry took %v, want it once the window resets", elapsed)
+	}
+}
+
+func TestRetryWaitCancelled(t *testing.T) {
+	s := newServer(t, middleware.NewRateLimiter(1, time.Minute), nil)
+	c := client.New(s.URL)
+	if _, err := c.GetState(context.Background(), "32", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.GetState(ctx, "32", nil)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, client.ErrRateLimitExceeded) {
+		t.Fatalf("got %v, want the deadline and the 429 error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("returned after %v, want once the context is done", elapsed)
+	}
+	if n := s.requests.Load(); n != 2 {
+		t.Fatalf("got %d requests, want no retry after cancelling", n)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	v2 := newServer(t, middleware.NewRateLimiter(middleware.DefaultLimitAnonymous, middleware.RateLimitWindow), nil)
+	// v1 serves the same requests under /v1, answering errors with the v1
+	// envelope instead of problem details.
+	v1 := newServer(t, unlimited(), func(path string) string {
+		return "/v1" + strings.TrimPrefix(path, "/v2")
+	})
+	ctx := context.Background()
+
+	manyCodes := make([]string, 700)
+	for i := range manyCodes {
+		manyCodes[i] = "32.73"
+	}
+
+	tests := []struct {
+		name       string
+		call       func(baseURL string) error
+		wantStatus int
+		wantErr    error
+		wantMsg    string
+	}{
+		{
+			name:       "not found",
+			call:       func(baseURL string) error { _, err := client.New(baseURL).GetCity(ctx, "99.99", nil); return err },
+			wantStatus: http.StatusNotFound,
+			wantErr:    client.ErrNotFound,
+			wantMsg:    "city not found",
+		},
+		{
+			name:       "bad request",
+			call:       func(baseURL string) error { _, err := client.New(baseURL).DecodeNIK(ctx, "123"); return err },
+			wantStatus: http.StatusBadRequest,
+			wantErr:    client.ErrBadRequest,
+		},
+		{
+			name: "invalid API key",
+			call: func(baseURL string) error {
+				_, err := client.New(baseURL, client.WithAPIKey("wrong")).GetState(ctx, "32", nil)
+				return err
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    client.ErrInvalidAPIKey,
+		},
+	}
+	for _, srv := range []struct {
+		name string
+		url  string
+	}{{"v2", v2.URL}, {"v1", v1.URL}} {
+		for _, tt := range tests {
+			t.Run(srv.name+"/"+tt.name, func(t *testing.T) {
+				err := tt.call(srv.url)
+				var apiErr *client.Error
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("got %v, want *client.Error", err)
+				}
+				if apiErr.StatusCode != tt.wantStatus || !errors.Is(err, tt.wantErr) || apiErr.RequestID == "" {
+					t.Fatalf("got %#v, want status %d and %v with a request ID", apiErr, tt.wantStatus, tt.wantErr)
+				}
+				if tt.wantMsg != "" && apiErr.Message != tt.wantMsg {
+					t.Fatalf("got message %q, want %q", apiErr.Message, tt.wantMsg)
+				}
+				if errors.Is(err, client.ErrServiceUnavailable) {
+					t.Fatalf("%v matches ErrServiceUnavailable", err)
+				}
+			})
+		}
+	}
+
+	// A batch costing more than the anonymous limit is refused, not retried.
+	_, err := client.New(v2.URL).BatchGet(ctx, manyCodes, false)
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, client.ErrCostExceedsLimit) || apiErr.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("BatchGet of 700 codes: got %v, want a 413 COST_EXCEEDS_LIMIT error", err)
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	err := error(&client.Error{StatusCode: http.StatusNotFound, Code: client.CodeNotFound, Message: "city not found"})
+	if !errors.Is(err, client.ErrNotFound) || errors.Is(err, client.ErrBadRequest) {
+		t.Fatal("Is does not match by code")
+	}
+	if !errors.Is(fmt.Errorf("wrapped: %w", err), client.ErrNotFound) {
+		t.Fatal("Is does not match a wrapped error")
+	}
+}
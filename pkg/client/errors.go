@@ -0,0 +1,146 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Error codes returned by the API in Error.Code.
+const (
+	CodeBadRequest         = "BAD_REQUEST"
+	CodeNotFound           = "NOT_FOUND"
+	CodeInvalidAPIKey      = "INVALID_API_KEY"
+	CodeAPIKeyExpired      = "API_KEY_EXPIRED"
+	CodeAPIKeyDisabled     = "API_KEY_DISABLED"
+	CodeOriginNotAllowed   = "ORIGIN_NOT_ALLOWED"
+	CodeRateLimitExceeded  = "RATE_LIMIT_EXCEEDED"
+	CodeCostExceedsLimit   = "COST_EXCEEDS_LIMIT"
+	CodePayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	CodeInternal           = "INTERNAL_SERVER_ERROR"
+)
+
+// Sentinel errors to match an *Error by code with errors.Is.
+var (
+	ErrBadRequest         = &Error{Code: CodeBadRequest}
+	ErrNotFound           = &Error{Code: CodeNotFound}
+	ErrInvalidAPIKey      = &Error{Code: CodeInvalidAPIKey}
+	ErrAPIKeyExpired      = &Error{Code: CodeAPIKeyExpired}
+	ErrAPIKeyDisabled     = &Error{Code: CodeAPIKeyDisabled}
+	ErrOriginNotAllowed   = &Error{Code: CodeOriginNotAllowed}
+	ErrRateLimitExceeded  = &Error{Code: CodeRateLimitExceeded}
+	ErrCostExceedsLimit   = &Error{Code: CodeCostExceedsLimit}
+	ErrServiceUnavailable = &Error{Code: CodeServiceUnavailable}
+)
+
+// Error is an error response of the API, decoded from its RFC 7807 problem
+// details or, from servers answering with the v1 envelope, from that.
+type Error struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Code is the machine-readable error code, e.g. NOT_FOUND.
+	Code string
+	// Message describes the error, e.g. "city not found".
+	Message string
+	// Instance is the request path the error is about.
+	Instance string
+	// RequestID identifies the request in the server's logs.
+	RequestID string
+	// ResetAt is when the rate limit resets, for 429 responses.
+	ResetAt time.Time
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("geo-id: %d %s", e.StatusCode, e.Code)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Is reports whether target is an *Error with the same code, so that
+// errors.Is(err, ErrNotFound) matches any not found response.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// problem is an RFC 7807 problem details body.
+type problem struct {
+	Detail    string `json:"detail"`
+	Instance  string `json:"instance"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id"`
+}
+
+// v1Error is an error body of the v1 API: handlers send the code as
+// message and the message as error, and middleware an error object.
+type v1Error struct {
+	Message   string          `json:"message"`
+	Error     json.RawMessage `json:"error"`
+	RequestID string          `json:"request_id"`
+}
+
+// parseV1Error returns the code, message and request ID of a v1 error body.
+func parseV1Error(raw []byte) (code, message, requestID string, ok bool) {
+	var v1 v1Error
+	if json.Unmarshal(raw, &v1) != nil || len(v1.Error) == 0 {
+		return "", "", "", false
+	}
+	if json.Unmarshal(v1.Error, &message) == nil {
+		return v1.Message, message, v1.RequestID, v1.Message != ""
+	}
+	var obj struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		RequestID string `json:"request_id"`
+	}
+	if json.Unmarshal(v1.Error, &obj) != nil || obj.Code == "" {
+		return "", "", "", false
+	}
+	return obj.Code, obj.Message, obj.RequestID, true
+}
+
+// parseError builds an *Error from an error response and closes its body.
+// Responses that are not problem details, e.g. from a proxy, keep their
+// status and a code derived from it.
+func parseError(resp *http.Response) *Error {
+	defer discard(resp.Body)
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-ID"),
+	}
+	if reset, ok := resetTime(resp.Header); ok && resp.StatusCode == http.StatusTooManyRequests {
+		e.ResetAt = reset
+	}
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var p problem
+	if json.Unmarshal(raw, &p) == nil && p.Code != "" {
+		e.Code, e.Message, e.Instance = p.Code, p.Detail, p.Instance
+		if p.RequestID != "" {
+			e.RequestID = p.RequestID
+		}
+		return e
+	}
+	if code, message, requestID, ok := parseV1Error(raw); ok {
+		e.Code, e.Message = code, message
+		if requestID != "" {
+			e.RequestID = requestID
+		}
+		return e
+	}
+	e.Code = strings.ToUpper(strings.ReplaceAll(http.StatusText(resp.StatusCode), " ", "_"))
+	if e.Code == "" {
+		e.Code = "ERROR"
+	}
+	e.Message = strings.TrimSpace(string(raw))
+	return e
+}
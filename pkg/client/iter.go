@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/url"
+)
+
+// DefaultPageSize is the page size iterators request when ListOptions
+// sets no Limit.
+const DefaultPageSize = 1000
+
+// States iterates over the states, fetching pages as needed. Iteration
+// stops after the first error, which is yielded with a zero Region.
+func (c *Client) States(ctx context.Context, opts *ListOptions) iter.Seq2[Region, error] {
+	return c.all(ctx, "/states", opts)
+}
+
+// Cities iterates over the cities of a state; see States.
+func (c *Client) Cities(ctx context.Context, stateCode string, opts *ListOptions) iter.Seq2[Region, error] {
+	return c.all(ctx, "/states/"+url.PathEscape(stateCode)+"/cities", opts)
+}
+
+// Districts iterates over the districts of a city; see States.
+func (c *Client) Districts(ctx context.Context, cityCode string, opts *ListOptions) iter.Seq2[Region, error] {
+	return c.all(ctx, "/cities/"+url.PathEscape(cityCode)+"/districts", opts)
+}
+
+// Villages iterates over the villages of a district; see States.
+func (c *Client) Villages(ctx context.Context, districtCode string, opts *ListOptions) iter.Seq2[Region, error] {
+	return c.all(ctx, "/districts/"+url.PathEscape(districtCode)+"/villages", opts)
+}
+
+// Children iterates over the children of a region of any level; see
+// States.
+func (c *Client) Children(ctx context.Context, code string, opts *ListOptions) iter.Seq2[Region, error] {
+	return c.all(ctx, "/regions/"+url.PathEscape(code)+"/children", opts)
+}
+
+// all iterates over the region list at path, starting at opts.Offset or
+// opts.Cursor and following the next cursor of each page.
+func (c *Client) all(ctx context.Context, path string, opts *ListOptions) iter.Seq2[Region, error] {
+	return func(yield func(Region, error) bool) {
+		o := ListOptions{Limit: DefaultPageSize}
+		if opts != nil {
+			o = *opts
+			if o.Limit == 0 {
+				o.Limit = DefaultPageSize
+			}
+		}
+		for {
+			page, err := c.list(ctx, path, &o)
+			if err != nil {
+				yield(Region{}, err)
+				return
+			}
+			for _, r := range page.Items {
+				if !yield(r, nil) {
+					return
+				}
+			}
+			if page.Pagination.NextCursor == "" {
+				return
+			}
+			o.Cursor, o.Offset = page.Pagination.NextCursor, 0
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MaxBatchSize is the number of codes the API resolves per batch request;
+// BatchGet splits larger batches.
+const MaxBatchSize = 1000
+
+// Info returns the name and version of the API server.
+func (c *Client) Info(ctx context.Context) (*Info, error) {
+	var info Info
+	if _, err := c.getJSON(ctx, request{method: http.MethodGet, path: "/"}, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ListStates returns a page of the states.
+func (c *Client) ListStates(ctx context.Context, opts *ListOptions) (*Page, error) {
+	return c.list(ctx, "/states", opts)
+}
+
+// GetState returns the state with the given code, e.g. "11".
+func (c *Client) GetState(ctx context.Context, code string, opts *RegionOptions) (*Region, error) {
+	return c.region(ctx, "/states/"+url.PathEscape(code), opts)
+}
+
+// ListCities returns a page of the cities of a state.
+func (c *Client) ListCities(ctx context.Context, stateCode string, opts *ListOptions) (*Page, error) {
+	return c.list(ctx, "/states/"+url.PathEscape(stateCode)+"/cities", opts)
+}
+
+// GetCity returns the city or regency with the given code, e.g. "11.01".
+func (c *Client) GetCity(ctx context.Context, code string, opts *RegionOptions) (*Region, error) {
+	return c.region(ctx, "/cities/"+url.PathEscape(code), opts)
+}
+
+// ListDistricts returns a page of the districts of a city.
+func (c *Client) ListDistricts(ctx context.Context, cityCode string, opts *ListOptions) (*Page, error) {
+	return c.list(ctx, "/cities/"+url.PathEscape(cityCode)+"/districts", opts)
+}
+
+// GetDistrict returns the district with the given code, e.g. "11.01.01".
+func (c *Client) GetDistrict(ctx context.Context, code string, opts *RegionOptions) (*Region, error) {
+	return c.region(ctx, "/districts/"+url.PathEscape(code), opts)
+}
+
+// ListVillages returns a page of the villages of a district.
+func (c *Client) ListVillages(ctx context.Context, districtCode string, opts *ListOptions) (*Page, error) {
+	return c.list(ctx, "/districts/"+url.PathEscape(districtCode)+"/villages", opts)
+}
+
+// GetVillage returns the village with the given code, e.g. "11.01.01.2001".
+func (c *Client) GetVillage(ctx context.Context, code string) (*Region, error) {
+	return c.region(ctx, "/villages/"+url.PathEscape(code), nil)
+}
+
+// GetRegion returns the region of any level with the given code, with its
+// level, type and links.
+func (c *Client) GetRegion(ctx context.Context, code string) (*RegionDetail, error) {
+	var detail RegionDetail
+	if _, err := c.getJSON(ctx, request{method: http.MethodGet, path: "/regions/" + url.PathEscape(code)}, &detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+// ListChildren returns a page of the direct children of the region of any
+// level with the given code. Villages have no children.
+func (c *Client) ListChildren(ctx context.Context, code string, opts *ListOptions) (*Page, error) {
+	return c.list(ctx, "/regions/"+url.PathEscape(code)+"/children", opts)
+}
+
+// GetTree returns the region with the given code with its descendants
+// nested depth levels deep (0 to 3).
+func (c *Client) GetTree(ctx context.Context, code string, depth int) (*Region, error) {
+	var region Region
+	req := request{
+		method: http.MethodGet,
+		path:   "/regions/" + url.PathEscape(code) + "/tree",
+		query:  url.Values{"depth": {strconv.Itoa(depth)}},
+	}
+	if _, err := c.getJSON(ctx, req, &region); err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+// BatchGet resolves codes of mixed levels, returning one item per code in
+// input order; codes that cannot be resolved carry an item error. Batches
+// larger than MaxBatchSize are sent as several requests.
+func (c *Client) BatchGet(ctx context.Context, codes []string, withAncestors bool) ([]BatchItem, error) {
+	items := make([]BatchItem, 0, len(codes))
+	for start := 0; start < len(codes); start += MaxBatchSize {
+		end := min(start+MaxBatchSize, len(codes))
+		req := request{
+			method: http.MethodPost,
+			path:   "/regions/batch",
+			body: struct {
+				Codes            []string `json:"codes"`
+				IncludeAncestors bool     `json:"include_ancestors"`
+			}{codes[start:end], withAncestors},
+		}
+		var chunk []BatchItem
+		if _, err := c.getJSON(ctx, req, &chunk); err != nil {
+			return nil, err
+		}
+		items = append(items, chunk...)
+	}
+	return items, nil
+}
+
+// Export streams the flattened hierarchy in the requested format. The
+// caller must close the returned export.
+func (c *Client) Export(ctx context.Context, opts *ExportOptions) (*Export, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	query := url.Values{}
+	setQuery(query, "format", opts.Format)
+	setQuery(query, "level", opts.Level)
+	setQuery(query, "within", opts.Within)
+	resp, err := c.do(ctx, request{method: http.MethodGet, path: "/export", query: query, accept: "*/*"})
+	if err != nil {
+		return nil, err
+	}
+	export := &Export{Body: resp.Body, Edition: resp.Header.Get("X-Data-Edition")}
+	export.UpdatedAt, _ = time.Parse(time.RFC3339, resp.Header.Get("X-Data-Updated-At"))
+	return export, nil
+}
+
+// ExportRecords streams the regions of an export as records. opts.Format
+// is ignored.
+func (c *Client) ExportRecords(ctx context.Context, opts *ExportOptions) iter.Seq2[ExportRecord, error] {
+	return func(yield func(ExportRecord, error) bool) {
+		o := ExportOptions{Format: FormatNDJSON}
+		if opts != nil {
+			o.Level, o.Within = opts.Level, opts.Within
+		}
+		export, err := c.Export(ctx, &o)
+		if err != nil {
+			yield(ExportRecord{}, err)
+			return
+		}
+		defer export.Close()
+
+		scanner := bufio.NewScanner(export)
+		for scanner.Scan() {
+			var record ExportRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				yield(ExportRecord{}, fmt.Errorf("decoding export: %w", err))
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(ExportRecord{}, err)
+		}
+	}
+}
+
+// DecodeNIK checks the structure of a NIK and decodes its region of
+// registration, birth date, gender and serial. A malformed NIK fails with
+// ErrBadRequest.
+func (c *Client) DecodeNIK(ctx context.Context, nik string) (*NIKDecoding, error) {
+	var decoded NIKDecoding
+	if _, err := c.getJSON(ctx, request{method: http.MethodGet, path: "/nik/" + url.PathEscape(nik) + "/decode"}, &decoded); err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
+
+// list fetches one page of the region list at path.
+func (c *Client) list(ctx context.Context, path string, opts *ListOptions) (*Page, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+	query := url.Values{}
+	if opts.Limit != 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset != 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	setQuery(query, "cursor", opts.Cursor)
+	setQuery(query, "sort", opts.Sort)
+	setQuery(query, "q", opts.Query)
+
+	page := &Page{}
+	pagination, err := c.getJSON(ctx, request{method: http.MethodGet, path: path, query: query}, &page.Items)
+	if err != nil {
+		return nil, err
+	}
+	if pagination != nil {
+		page.Pagination = *pagination
+	}
+	return page, nil
+}
+
+// region fetches the single region at path.
+func (c *Client) region(ctx context.Context, path string, opts *RegionOptions) (*Region, error) {
+	query := url.Values{}
+	if opts != nil {
+		setQuery(query, "expand", opts.Expand)
+	}
+	var region Region
+	if _, err := c.getJSON(ctx, request{method: http.MethodGet, path: path, query: query}, &region); err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"io"
+	"time"
+)
+
+// Region is a region of any level, with its descendants when requested.
+type Region struct {
+	Code     string   `json:"code"`
+	Name     string   `json:"value"`
+	Children []Region `json:"children,omitempty"`
+}
+
+// RegionDetail is a region together with its level, type and the API paths
+// of its parent and children.
+type RegionDetail struct {
+	Code       string      `json:"code"`
+	Name       string      `json:"value"`
+	Level      string      `json:"level"`
+	Type       string      `json:"type"`
+	ParentCode string      `json:"parent_code,omitempty"`
+	Links      RegionLinks `json:"links"`
+}
+
+// RegionLinks holds the API paths of a region and its related resources.
+// Parent is empty for states and Children is empty for villages.
+type RegionLinks struct {
+	Self     string `json:"self"`
+	Parent   string `json:"parent,omitempty"`
+	Children string `json:"children,omitempty"`
+}
+
+// BatchItem is the result for one code of a batch lookup. Exactly one of
+// Region or Error is set.
+type BatchItem struct {
+	Code      string      `json:"code"`
+	Level     string      `json:"level,omitempty"`
+	Region    *Region     `json:"region,omitempty"`
+	Ancestors []Region    `json:"ancestors,omitempty"`
+	Error     *BatchError `json:"error,omitempty"`
+}
+
+// BatchError describes why a code of a batch could not be resolved.
+type BatchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Pagination describes the page of a list.
+type Pagination struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Page is one page of a region list.
+type Page struct {
+	Items      []Region
+	Pagination Pagination
+}
+
+// ListOptions selects the page, order and filter of a region list. The
+// zero value requests the whole list in code order.
+type ListOptions struct {
+	// Limit is the page size, at most 1000; 0 returns the whole list.
+	Limit int
+	// Offset skips this many regions. It cannot be combined with Cursor.
+	Offset int
+	// Cursor continues from the NextCursor of a previous page.
+	Cursor string
+	// Sort is "code" (the default) or "name".
+	Sort string
+	// Query only lists regions whose name contains it, ignoring case.
+	Query string
+}
+
+// RegionOptions controls how a single region is returned.
+type RegionOptions struct {
+	// Expand nests child levels below the region, e.g. "districts" or
+	// "districts.villages" for a city.
+	Expand string
+}
+
+// Export formats.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatJSON   = "json"
+)
+
+// ExportOptions selects the format and regions of an export.
+type ExportOptions struct {
+	// Format is FormatCSV (the default), FormatNDJSON or FormatJSON.
+	Format string
+	// Level only exports regions of this level: state, city, district or
+	// village.
+	Level string
+	// Within only exports this region and its descendants.
+	Within string
+}
+
+// ExportRecord is one region of an export with the codes and names of its
+// parents, from the state down.
+type ExportRecord struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Level       string   `json:"level"`
+	Type        string   `json:"type"`
+	ParentCodes []string `json:"parent_codes"`
+	ParentNames []string `json:"parent_names"`
+}
+
+// Export is a streamed export. The caller must close it.
+type Export struct {
+	// Body is the export in the requested format.
+	Body io.ReadCloser
+	// Edition and UpdatedAt identify the dataset release exported.
+	Edition   string
+	UpdatedAt time.Time
+}
+
+// Read reads from the export body.
+func (e *Export) Read(p []byte) (int, error) {
+	return e.Body.Read(p)
+}
+
+// Close closes the export body.
+func (e *Export) Close() error {
+	return e.Body.Close()
+}
+
+// NIKDecoding is a NIK decoded into its region of registration, birth
+// date, gender and serial.
+type NIKDecoding struct {
+	NIK string `json:"nik"`
+	// State, City and District are nil when the prefix does not resolve.
+	State    *Region `json:"state"`
+	City     *Region `json:"city"`
+	District *Region `json:"district"`
+	// RegionStatus is "current" when the district exists in the current
+	// edition, "past" when it only exists in a past edition and "unknown"
+	// otherwise.
+	RegionStatus string `json:"region_status"`
+	Edition      string `json:"edition,omitempty"`
+	// BirthDate is formatted as YYYY-MM-DD.
+	BirthDate string `json:"birth_date"`
+	Gender    string `json:"gender"`
+	Serial    string `json:"serial"`
+}
+
+// Info describes the API server.
+type Info struct {
+	App     string `json:"app"`
+	Version string `json:"version"`
+	DocsURL string `json:"docs_url"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,168 @@
+package geoid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Level identifies the administrative level of a region.
+type Level string
+
+const (
+	// LevelState is a province (provinsi), e.g. "11".
+	LevelState Level = "state"
+	// LevelCity is a regency (kabupaten) or city (kota), e.g. "11.01".
+	LevelCity Level = "city"
+	// LevelDistrict is a district (kecamatan), e.g. "11.01.01".
+	LevelDistrict Level = "district"
+	// LevelVillage is a rural (desa) or urban (kelurahan) village, e.g.
+	// "11.01.01.2001".
+	LevelVillage Level = "village"
+)
+
+// ErrInvalidCode is returned when a code does not match any level's format.
+var ErrInvalidCode = errors.New("invalid region code")
+
+// levels lists the hierarchy from top to bottom together with the digit
+// width of the code segment each level appends and the plural name of the
+// level.
+var levels = []struct {
+	level  Level
+	width  int
+	plural string
+}{
+	{LevelState, 2, "states"},
+	{LevelCity, 2, "cities"},
+	{LevelDistrict, 2, "districts"},
+	{LevelVillage, 4, "villages"},
+}
+
+// Levels returns every level from the top (states) down to villages.
+func Levels() []Level {
+	out := make([]Level, len(levels))
+	for i, l := range levels {
+		out[i] = l.level
+	}
+	return out
+}
+
+// Valid reports whether l is one of the four levels.
+func (l Level) Valid() bool {
+	return l.depth() >= 0
+}
+
+// Plural returns the plural name of the level, e.g. "cities", as used for
+// data directories and API paths. It is empty for an invalid level.
+func (l Level) Plural() string {
+	if d := l.depth(); d >= 0 {
+		return levels[d].plural
+	}
+	return ""
+}
+
+// Child returns the level directly below l, or "" for villages.
+func (l Level) Child() Level {
+	if d := l.depth(); d >= 0 && d < len(levels)-1 {
+		return levels[d+1].level
+	}
+	return ""
+}
+
+// Parent returns the level directly above l, or "" for states.
+func (l Level) Parent() Level {
+	if d := l.depth(); d > 0 {
+		return levels[d-1].level
+	}
+	return ""
+}
+
+// depth returns the index of l in levels, or -1.
+func (l Level) depth() int {
+	for i := range levels {
+		if levels[i].level == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// LevelOf infers the administrative level from the shape of a code:
+// "11" (state), "11.01" (city), "11.01.01" (district), "11.01.01.2001"
+// (village). It returns ErrInvalidCode for any other shape.
+func LevelOf(code string) (Level, error) {
+	segments := strings.Split(code, ".")
+	if len(segments) > len(levels) {
+		return "", ErrInvalidCode
+	}
+	for i, seg := range segments {
+		if len(seg) != levels[i].width || !isDigits(seg) {
+			return "", ErrInvalidCode
+		}
+	}
+	return levels[len(segments)-1].level, nil
+}
+
+// ParseCode parses a code written with or without dots, such as
+// "32.73.01.1001" or "3273011001", ignoring surrounding spaces. It returns
+// the dotted code and its level, and fails with ErrInvalidCode when s is
+// not a code of any level.
+func ParseCode(s string) (string, Level, error) {
+	code := strings.TrimSpace(s)
+	if !strings.Contains(code, ".") {
+		var b strings.Builder
+		for i, rest := 0, code; rest != ""; i++ {
+			if i == len(levels) || len(rest) < levels[i].width {
+				return "", "", fmt.Errorf("%w: %q", ErrInvalidCode, s)
+			}
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(rest[:levels[i].width])
+			rest = rest[levels[i].width:]
+		}
+		code = b.String()
+	}
+	level, err := LevelOf(code)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidCode, s)
+	}
+	return code, level, nil
+}
+
+// ValidCode reports whether code is a well-formed code of any level.
+func ValidCode(code string) bool {
+	_, err := LevelOf(code)
+	return err == nil
+}
+
+// ParentCode returns the code of the region directly above code, or "" for
+// states.
+func ParentCode(code string) string {
+	idx := strings.LastIndexByte(code, '.')
+	if idx < 0 {
+		return ""
+	}
+	return code[:idx]
+}
+
+// AncestorCodes returns the codes of the regions above code, from the state
+// down to the direct parent. It is empty for states.
+func AncestorCodes(code string) []string {
+	var codes []string
+	for i := 0; i < len(code); i++ {
+		if code[i] == '.' {
+			codes = append(codes, code[:i])
+		}
+	}
+	return codes
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
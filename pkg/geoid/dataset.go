@@ -0,0 +1,392 @@
+package geoid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UnknownEdition names datasets generated without an edition.json file.
+const UnknownEdition = "unknown"
+
+var (
+	// ErrNotFound is returned when a well-formed code is not in the dataset.
+	ErrNotFound = errors.New("region not found")
+	// ErrInvalidDataset is returned by Validate when the dataset is
+	// incomplete or inconsistent.
+	ErrInvalidDataset = errors.New("invalid dataset")
+)
+
+// Edition describes the release of the administrative dataset.
+type Edition struct {
+	// Name is the decree the codes follow, e.g. "Kepmendagri No
+	// 300.2.2-2138 Tahun 2025".
+	Name string `json:"name"`
+	// Source is the URL the dataset was imported from.
+	Source string `json:"source"`
+	// UpdatedAt is when the source was last edited.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Counts is the number of regions per level.
+type Counts struct {
+	States    int `json:"states"`
+	Cities    int `json:"cities"`
+	Districts int `json:"districts"`
+	Villages  int `json:"villages"`
+}
+
+// Dataset is an in-memory set of regions indexed by code and by parent.
+type Dataset struct {
+	edition Edition
+	regions map[string]Region
+	// children maps every parent code to its children in source order;
+	// states are listed under "".
+	children map[string][]Region
+	// codes lists every code in order, with the lowercased name of each
+	// region at the same index of names for search.
+	codes []string
+	names []string
+}
+
+func newDataset(edition Edition) *Dataset {
+	return &Dataset{
+		edition:  edition,
+		regions:  make(map[string]Region),
+		children: make(map[string][]Region),
+	}
+}
+
+// add indexes r. Regions must be added parents first within a list to keep
+// children in source order.
+func (d *Dataset) add(r Region) {
+	if _, ok := d.regions[r.Code]; ok {
+		return
+	}
+	d.regions[r.Code] = r
+	parent := ParentCode(r.Code)
+	d.children[parent] = append(d.children[parent], r)
+}
+
+// index builds the search index once every region has been added. Codes
+// have fixed-width segments, so sorting them as strings orders them by
+// hierarchy and number.
+func (d *Dataset) index() {
+	d.codes = make([]string, 0, len(d.regions))
+	for code := range d.regions {
+		d.codes = append(d.codes, code)
+	}
+	sort.Strings(d.codes)
+	d.names = make([]string, len(d.codes))
+	for i, code := range d.codes {
+		d.names[i] = strings.ToLower(d.regions[code].Name)
+	}
+}
+
+// fileRegion is a region as stored in the data directory.
+type fileRegion struct {
+	Code  string `json:"code"`
+	Value string `json:"value"`
+}
+
+// Load reads a dataset from fsys, laid out like the data directory
+// generated by "geo-id import": states.json, one file per parent under
+// cities/, districts/ and villages/, and edition.json. Without
+// edition.json the edition is named UnknownEdition and dated by the
+// modification time of states.json.
+func Load(fsys fs.FS) (*Dataset, error) {
+	edition, err := loadEdition(fsys)
+	if err != nil {
+		return nil, err
+	}
+	d := newDataset(edition)
+
+	files := []string{"states.json"}
+	for _, l := range levels[1:] {
+		matches, err := fs.Glob(fsys, path.Join(l.plural, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	for _, file := range files {
+		var regions []fileRegion
+		if err := readJSON(fsys, file, &regions); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		for _, r := range regions {
+			d.add(Region{Code: r.Code, Name: r.Value})
+		}
+	}
+	d.index()
+	return d, nil
+}
+
+// LoadDir reads the dataset in the data directory dir; see Load.
+func LoadDir(dir string) (*Dataset, error) {
+	d, err := Load(os.DirFS(dir))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	return d, nil
+}
+
+func loadEdition(fsys fs.FS) (Edition, error) {
+	var edition Edition
+	err := readJSON(fsys, "edition.json", &edition)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return edition, fmt.Errorf("reading edition.json: %w", err)
+	}
+	if edition.Name == "" {
+		edition.Name = UnknownEdition
+	}
+	if edition.UpdatedAt.IsZero() {
+		info, err := fs.Stat(fsys, "states.json")
+		if err != nil {
+			return edition, fmt.Errorf("reading states.json: %w", err)
+		}
+		edition.UpdatedAt = info.ModTime()
+	}
+	return edition, nil
+}
+
+func readJSON(fsys fs.FS, name string, v interface{}) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewDecoder(file).Decode(v)
+}
+
+// Edition returns the release of the dataset.
+func (d *Dataset) Edition() Edition {
+	return d.edition
+}
+
+// Len returns the number of regions in the dataset.
+func (d *Dataset) Len() int {
+	return len(d.regions)
+}
+
+// Counts returns the number of regions per level.
+func (d *Dataset) Counts() Counts {
+	var counts Counts
+	for _, code := range d.codes {
+		switch level, _ := LevelOf(code); level {
+		case LevelState:
+			counts.States++
+		case LevelCity:
+			counts.Cities++
+		case LevelDistrict:
+			counts.Districts++
+		case LevelVillage:
+			counts.Villages++
+		}
+	}
+	return counts
+}
+
+// Region returns the region with the given code. It fails with
+// ErrInvalidCode for a malformed code and ErrNotFound for an unknown one.
+func (d *Dataset) Region(code string) (Region, error) {
+	if !ValidCode(code) {
+		return Region{}, fmt.Errorf("%w: %q", ErrInvalidCode, code)
+	}
+	r, ok := d.regions[code]
+	if !ok {
+		return Region{}, fmt.Errorf("%w: %s", ErrNotFound, code)
+	}
+	return r, nil
+}
+
+// Lookup returns the region with the given code and whether it exists.
+func (d *Dataset) Lookup(code string) (Region, bool) {
+	r, ok := d.regions[code]
+	return r, ok
+}
+
+// States returns every state in source order.
+func (d *Dataset) States() []Region {
+	return clone(d.children[""])
+}
+
+// Children returns the direct children of the region with the given code,
+// in source order. Villages have no children. It fails like Region when the
+// region does not exist.
+func (d *Dataset) Children(code string) ([]Region, error) {
+	if _, err := d.Region(code); err != nil {
+		return nil, err
+	}
+	return clone(d.children[code]), nil
+}
+
+// Parent returns the region directly above the region with the given code.
+// States have no parent, so it fails with ErrNotFound for them.
+func (d *Dataset) Parent(code string) (Region, error) {
+	if !ValidCode(code) {
+		return Region{}, fmt.Errorf("%w: %q", ErrInvalidCode, code)
+	}
+	parent := ParentCode(code)
+	if parent == "" {
+		return Region{}, fmt.Errorf("%w: state %s has no parent", ErrNotFound, code)
+	}
+	return d.Region(parent)
+}
+
+// Ancestors returns the regions above the region with the given code, from
+// the state down to the direct parent. It fails when any of them is
+// missing.
+func (d *Dataset) Ancestors(code string) ([]Region, error) {
+	if _, err := d.Region(code); err != nil {
+		return nil, err
+	}
+	codes := AncestorCodes(code)
+	ancestors := make([]Region, len(codes))
+	for i, c := range codes {
+		r, err := d.Region(c)
+		if err != nil {
+			return nil, err
+		}
+		ancestors[i] = r
+	}
+	return ancestors, nil
+}
+
+// SkipChildren can be returned by a WalkFunc to skip the descendants of the
+// region it was called for.
+var SkipChildren = errors.New("skip children")
+
+// WalkFunc is called by Walk for each region.
+type WalkFunc func(r Region) error
+
+// Walk calls fn for the region with the given code and each of its
+// descendants, depth first in source order. An empty code walks every
+// state. When fn returns SkipChildren the descendants of that region are
+// skipped; any other error stops the walk and is returned.
+func (d *Dataset) Walk(code string, fn WalkFunc) error {
+	if code == "" {
+		return d.walkChildren("", fn)
+	}
+	r, err := d.Region(code)
+	if err != nil {
+		return err
+	}
+	return d.walk(r, fn)
+}
+
+func (d *Dataset) walk(r Region, fn WalkFunc) error {
+	if err := fn(r); err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	return d.walkChildren(r.Code, fn)
+}
+
+func (d *Dataset) walkChildren(code string, fn WalkFunc) error {
+	for _, child := range d.children[code] {
+		if err := d.walk(child, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchOptions restricts a Search.
+type SearchOptions struct {
+	// Level only matches regions of this level when set.
+	Level Level
+	// Within only matches descendants of the region with this code when
+	// set.
+	Within string
+	// Limit caps the number of matches; 0 means no limit.
+	Limit int
+}
+
+// Search returns the regions whose name contains q, ignoring case, ordered
+// by code. A blank q matches nothing.
+func (d *Dataset) Search(q string, opts SearchOptions) []Region {
+	needle := strings.ToLower(strings.TrimSpace(q))
+	matches := []Region{}
+	if needle == "" {
+		return matches
+	}
+	start, prefix := 0, ""
+	if opts.Within != "" {
+		prefix = opts.Within + "."
+		start = sort.SearchStrings(d.codes, prefix)
+	}
+	for i := start; i < len(d.codes); i++ {
+		code := d.codes[i]
+		if !strings.HasPrefix(code, prefix) {
+			break
+		}
+		if opts.Level != "" {
+			if l, _ := LevelOf(code); l != opts.Level {
+				continue
+			}
+		}
+		if strings.Contains(d.names[i], needle) {
+			matches = append(matches, d.regions[code])
+			if len(matches) == opts.Limit {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// Validate checks that every code is well-formed, no level is empty and
+// every city and district belongs to an existing parent. The source data
+// lists some villages under districts it no longer contains; these are not
+// an error, Validate returns how many there are. Such villages can still be
+// looked up by code.
+func (d *Dataset) Validate() (orphans int, err error) {
+	for _, code := range d.codes {
+		level, err := LevelOf(code)
+		if err != nil {
+			return 0, fmt.Errorf("%w: malformed code %q", ErrInvalidDataset, code)
+		}
+		parent := ParentCode(code)
+		if parent == "" {
+			continue
+		}
+		if _, ok := d.regions[parent]; !ok {
+			if level == LevelVillage {
+				orphans++
+				continue
+			}
+			return 0, fmt.Errorf("%w: parent %s of %s does not exist", ErrInvalidDataset, parent, code)
+		}
+	}
+
+	counts := d.Counts()
+	for _, c := range []struct {
+		level Level
+		n     int
+	}{
+		{LevelState, counts.States},
+		{LevelCity, counts.Cities},
+		{LevelDistrict, counts.Districts},
+		{LevelVillage, counts.Villages},
+	} {
+		if c.n == 0 {
+			return 0, fmt.Errorf("%w: no %s regions", ErrInvalidDataset, c.level)
+		}
+	}
+	return orphans, nil
+}
+
+func clone(regions []Region) []Region {
+	out := make([]Region, len(regions))
+	copy(out, regions)
+	return out
+}
@@ -0,0 +1,52 @@
+// Package geoid provides the Indonesian administrative regions served by
+// the geo-id API as a Go library: region codes and levels, lookups by code,
+// navigation of the province, regency/city, district and village hierarchy,
+// and search by name. Other Go services can embed it instead of calling the
+// API over HTTP.
+//
+// A Dataset is loaded from a data directory generated by "geo-id import",
+// from any fs.FS with the same layout, or from a snapshot written by
+// WriteSnapshot. The geoid/embedded package compiles a snapshot of the
+// current edition into the binary:
+//
+//	ds, err := embedded.Load()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	city, err := ds.Region("32.73")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(city.Name, city.Type()) // Kota Bandung kota
+//
+// Loading a data directory instead:
+//
+//	ds, err := geoid.LoadDir("data")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	districts, _ := ds.Children("32.73")
+//	ancestors, _ := ds.Ancestors("32.73.01.1001")     // Jawa Barat, Kota Bandung, Sukasari
+//	matches := ds.Search("bandung", geoid.SearchOptions{Level: geoid.LevelCity})
+//
+// Codes can be checked without a dataset:
+//
+//	level, err := geoid.LevelOf("11.01.01")   // district
+//	parent := geoid.ParentCode("11.01.01")    // 11.01
+//	code, _, err := geoid.ParseCode("110101") // 11.01.01
+//
+// A Dataset is immutable once loaded and safe for concurrent use. Regions
+// and slices returned by it are copies the caller may modify.
+//
+// # Versioning
+//
+// The package follows semantic versioning as part of the
+// github.com/ikhsanfalakh/geo-id module: releases are tagged vMAJOR.MINOR.PATCH
+// and exported identifiers are only removed or changed incompatibly in a new
+// major version. Version is the version of the package API. The dataset has
+// its own edition, reported by Dataset.Edition; a new edition of the data
+// is released as a patch version.
+package geoid
+
+// Version is the semantic version of the geoid package API.
+const Version = "1.1.0"
@@ -0,0 +1,31 @@
+//go:build ignore
+
+// gen writes regions.json.gz from the data directory of the repository.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
+)
+
+func main() {
+	ds, err := geoid.LoadDir("../../../data")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := ds.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := ds.WriteSnapshot(&buf); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("regions.json.gz", buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Wrote %d regions of %s (%d bytes)\n", ds.Len(), ds.Edition().Name, buf.Len())
+}
@@ -0,0 +1,73 @@
+package geoid_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid"
+	"github.com/ikhsanfalakh/geo-id/pkg/geoid/embedded"
+)
+
+func ExampleDataset_Lookup() {
+	ds, err := embedded.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if city, ok := ds.Lookup("32.73"); ok {
+		fmt.Println(city.Name, city.Level(), city.Type())
+	}
+	if _, ok := ds.Lookup("32.99"); !ok {
+		fmt.Println("32.99 does not exist")
+	}
+	// Output:
+	// Kota Bandung city kota
+	// 32.99 does not exist
+}
+
+func ExampleParseCode() {
+	for _, s := range []string{"3273011001", " 32.73 ", "32730"} {
+		code, level, err := geoid.ParseCode(s)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(code, level)
+	}
+	// Output:
+	// 32.73.01.1001 village
+	// 32.73 city
+	// invalid region code: "32730"
+}
+
+func ExampleDataset_Children() {
+	ds, err := embedded.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	villages, err := ds.Children("32.73.01")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, v := range villages {
+		fmt.Println(v.Code, v.Name, v.Type())
+	}
+	// Output:
+	// 32.73.01.1001 Sukarasa kelurahan
+	// 32.73.01.1002 Gegerkalong kelurahan
+	// 32.73.01.1003 Isola kelurahan
+	// 32.73.01.1004 Sarijadi kelurahan
+}
+
+func ExampleDataset_Search() {
+	ds, err := embedded.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, city := range ds.Search("bandung", geoid.SearchOptions{Level: geoid.LevelCity}) {
+		fmt.Println(city.Code, city.Name)
+	}
+	// Output:
+	// 32.04 Kabupaten Bandung
+	// 32.17 Kabupaten Bandung Barat
+	// 32.73 Kota Bandung
+}
@@ -0,0 +1,167 @@
+package geoid
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NIKLength is the number of digits of a NIK.
+const NIKLength = 16
+
+// ErrInvalidNIK is returned when a NIK is not structurally valid.
+var ErrInvalidNIK = errors.New("invalid NIK")
+
+// Gender is the gender encoded in a NIK.
+type Gender string
+
+const (
+	GenderMale   Gender = "male"
+	GenderFemale Gender = "female"
+)
+
+// NIK is a decoded Nomor Induk Kependudukan, the national identity number:
+// six digits of the district the holder was registered in, the birth date
+// as DDMMYY with 40 added to the day for women, and a four digit serial.
+type NIK struct {
+	// Number is the NIK itself.
+	Number string
+	// DistrictCode is the district of registration in dotted form, e.g.
+	// "32.73.01".
+	DistrictCode string
+	BirthDate    time.Time
+	Gender       Gender
+	// Serial is the four digit sequence number, e.g. "0001".
+	Serial string
+}
+
+// StateCode returns the code of the state of registration, e.g. "32", or
+// "" for the zero NIK.
+func (n NIK) StateCode() string {
+	if len(n.DistrictCode) < 2 {
+		return ""
+	}
+	return n.DistrictCode[:2]
+}
+
+// CityCode returns the code of the city of registration, e.g. "32.73", or
+// "" for the zero NIK.
+func (n NIK) CityCode() string {
+	if len(n.DistrictCode) < 5 {
+		return ""
+	}
+	return n.DistrictCode[:5]
+}
+
+// ParseNIK checks the structure of nik and decodes it. Two digit birth
+// years are placed in the last hundred years relative to now. It fails with
+// ErrInvalidNIK, explaining which part is wrong.
+func ParseNIK(nik string) (NIK, error) {
+	return parseNIK(nik, time.Now())
+}
+
+// parseNIK is ParseNIK at the time now.
+func parseNIK(nik string, now time.Time) (NIK, error) {
+	if len(nik) != NIKLength || !isDigits(nik) {
+		return NIK{}, fmt.Errorf("%w: must be %d digits", ErrInvalidNIK, NIKLength)
+	}
+	n := NIK{
+		Number:       nik,
+		DistrictCode: nik[0:2] + "." + nik[2:4] + "." + nik[4:6],
+		Gender:       GenderMale,
+		Serial:       nik[12:16],
+	}
+	if nik[0:2] == "00" || nik[2:4] == "00" || nik[4:6] == "00" {
+		return NIK{}, fmt.Errorf("%w: region prefix %s is not a district code", ErrInvalidNIK, nik[0:6])
+	}
+	if n.Serial == "0000" {
+		return NIK{}, fmt.Errorf("%w: serial must not be 0000", ErrInvalidNIK)
+	}
+
+	now = now.UTC()
+	day, month, year := atoi2(nik[6:8]), atoi2(nik[8:10]), atoi2(nik[10:12])
+	if day > 40 {
+		day -= 40
+		n.Gender = GenderFemale
+	}
+	year += now.Year() / 100 * 100
+	if year > now.Year() {
+		year -= 100
+	}
+	n.BirthDate = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if day < 1 || month < 1 || month > 12 || n.BirthDate.Day() != day {
+		return NIK{}, fmt.Errorf("%w: birth date %s is not a valid DDMMYY date (add 40 to the day for women)", ErrInvalidNIK, nik[6:12])
+	}
+	if n.BirthDate.After(now) {
+		return NIK{}, fmt.Errorf("%w: birth date %s is in the future", ErrInvalidNIK, n.BirthDate.Format(time.DateOnly))
+	}
+	return n, nil
+}
+
+// atoi2 parses two ASCII digits.
+func atoi2(s string) int {
+	return int(s[0]-'0')*10 + int(s[1]-'0')
+}
+
+// RegionStatus tells whether the region prefix of a NIK exists.
+type RegionStatus string
+
+const (
+	// RegionCurrent means the district exists in the current edition.
+	RegionCurrent RegionStatus = "current"
+	// RegionPast means the district only exists in a past edition, e.g.
+	// because it has since been split or renumbered.
+	RegionPast RegionStatus = "past"
+	// RegionUnknown means the district exists in no known edition, which
+	// suggests a mistyped or forged NIK.
+	RegionUnknown RegionStatus = "unknown"
+)
+
+// NIKRegion is the region of registration of a NIK resolved against a
+// dataset.
+type NIKRegion struct {
+	NIK
+	// State, City and District are the regions of registration, from the
+	// edition the district was found in. When it was not found they are
+	// whichever exist in the current edition, and nil otherwise.
+	State, City, District *Region
+	Status                RegionStatus
+	// Edition names the edition the district was found in.
+	Edition string
+}
+
+// DecodeNIK parses nik like ParseNIK and resolves its region prefix against
+// the current dataset d and then against past editions, newest first. A
+// prefix found in no edition is not an error; it is reported as
+// RegionUnknown.
+func (d *Dataset) DecodeNIK(nik string, past ...*Dataset) (*NIKRegion, error) {
+	n, err := ParseNIK(nik)
+	if err != nil {
+		return nil, err
+	}
+	r := &NIKRegion{NIK: n, Status: RegionUnknown}
+	for i, ds := range append([]*Dataset{d}, past...) {
+		if _, ok := ds.Lookup(n.DistrictCode); !ok {
+			continue
+		}
+		r.State, r.City, r.District = ds.nikRegions(n)
+		r.Status, r.Edition = RegionCurrent, ds.Edition().Name
+		if i > 0 {
+			r.Status = RegionPast
+		}
+		return r, nil
+	}
+	r.State, r.City, r.District = d.nikRegions(n)
+	return r, nil
+}
+
+// nikRegions looks up the state, city and district of n.
+func (d *Dataset) nikRegions(n NIK) (state, city, district *Region) {
+	find := func(code string) *Region {
+		if r, ok := d.Lookup(code); ok {
+			return &r
+		}
+		return nil
+	}
+	return find(n.StateCode()), find(n.CityCode()), find(n.DistrictCode)
+}
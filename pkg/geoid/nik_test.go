@@ -0,0 +1,158 @@
+package geoid
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestParseNIK(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name       string
+		nik        string
+		now        time.Time
+		wantBirth  time.Time
+		wantGender Gender
+		wantErr    bool
+	}{
+		{name: "male", nik: "3273010101900001", now: now, wantBirth: date(1990, 1, 1), wantGender: GenderMale},
+		{name: "female day plus 40", nik: "3273014101900001", now: now, wantBirth: date(1990, 1, 1), wantGender: GenderFemale},
+		{name: "female last day of month", nik: "3273017112850001", now: now, wantBirth: date(1985, 12, 31), wantGender: GenderFemale},
+		{name: "leap day", nik: "3273012902000001", now: now, wantBirth: date(2000, 2, 29), wantGender: GenderMale},
+		{name: "year of now", nik: "3273010101260001", now: now, wantBirth: date(2026, 1, 1), wantGender: GenderMale},
+		{name: "year after now rolls back a century", nik: "3273010101270001", now: now, wantBirth: date(1927, 1, 1), wantGender: GenderMale},
+		{name: "century rollover before 2000", nik: "3273010101000001", now: date(1999, 6, 1), wantBirth: date(1900, 1, 1), wantGender: GenderMale},
+		{name: "century rollover after 2000", nik: "3273010101990001", now: date(2000, 6, 1), wantBirth: date(1999, 1, 1), wantGender: GenderMale},
+		{name: "today", nik: "3273011910260001", now: now, wantBirth: date(2026, 10, 19), wantGender: GenderMale},
+		{name: "future date", nik: "3273012010260001", now: now, wantErr: true},
+		{name: "31 February", nik: "3273013102900001", now: now, wantErr: true},
+		{name: "29 February of a common year", nik: "3273012902010001", now: now, wantErr: true},
+		{name: "female 31 February", nik: "3273017102900001", now: now, wantErr: true},
+		{name: "day 00", nik: "3273010001900001", now: now, wantErr: true},
+		{name: "day 40", nik: "3273014001900001", now: now, wantErr: true},
+		{name: "day 72", nik: "3273017201900001", now: now, wantErr: true},
+		{name: "month 00", nik: "3273010100900001", now: now, wantErr: true},
+		{name: "month 13", nik: "3273010113900001", now: now, wantErr: true},
+		{name: "serial 0000", nik: "3273010101900000", now: now, wantErr: true},
+		{name: "state 00", nik: "0073010101900001", now: now, wantErr: true},
+		{name: "city 00", nik: "3200010101900001", now: now, wantErr: true},
+		{name: "district 00", nik: "3273000101900001", now: now, wantErr: true},
+		{name: "too short", nik: "327301010190001", now: now, wantErr: true},
+		{name: "too long", nik: "32730101019000011", now: now, wantErr: true},
+		{name: "not digits", nik: "32730101019O0001", now: now, wantErr: true},
+		{name: "dotted", nik: "32.73.01.0101900", now: now, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := parseNIK(tt.nik, tt.now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidNIK) {
+					t.Fatalf("got %+v, %v; want ErrInvalidNIK", n, err)
+				}
+				if n != (NIK{}) {
+					t.Fatalf("got %+v with the error, want the zero NIK", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !n.BirthDate.Equal(tt.wantBirth) || n.Gender != tt.wantGender {
+				t.Fatalf("got birth date %v, gender %s; want %v, %s", n.BirthDate, n.Gender, tt.wantBirth, tt.wantGender)
+			}
+			if n.Number != tt.nik || n.DistrictCode != "32.73.01" || n.Serial != tt.nik[12:] {
+				t.Fatalf("got %+v", n)
+			}
+			if n.StateCode() != "32" || n.CityCode() != "32.73" {
+				t.Fatalf("got state %q, city %q", n.StateCode(), n.CityCode())
+			}
+		})
+	}
+}
+
+func TestNIKZeroValue(t *testing.T) {
+	var n NIK
+	if n.StateCode() != "" || n.CityCode() != "" {
+		t.Fatalf("got state %q, city %q; want none", n.StateCode(), n.CityCode())
+	}
+}
+
+// nikDataset loads a dataset of the given regions, by level, in the
+// layout of the data directory.
+func nikDataset(t *testing.T, edition string, files map[string]string) *Dataset {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"edition.json": {Data: []byte(`{"name":"` + edition + `","updated_at":"2025-01-01T00:00:00Z"}`)},
+	}
+	for name, data := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	d, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDecodeNIK(t *testing.T) {
+	current := nikDataset(t, "2025", map[string]string{
+		"states.json":          `[{"code":"32","value":"Jawa Barat"}]`,
+		"cities/32.json":       `[{"code":"32.73","value":"Kota Bandung"}]`,
+		"districts/32.73.json": `[{"code":"32.73.01","value":"Sukasari"}]`,
+	})
+	// The past edition still has 32.73.99, since renumbered, and names
+	// the city differently.
+	past := nikDataset(t, "2022", map[string]string{
+		"states.json":          `[{"code":"32","value":"Jawa Barat"}]`,
+		"cities/32.json":       `[{"code":"32.73","value":"Kotamadya Bandung"}]`,
+		"districts/32.73.json": `[{"code":"32.73.01","value":"Sukasari"},{"code":"32.73.99","value":"Cidadap Lama"}]`,
+	})
+
+	tests := []struct {
+		name         string
+		nik          string
+		wantStatus   RegionStatus
+		wantEdition  string
+		wantState    string
+		wantCity     string
+		wantDistrict string
+	}{
+		{"current", "3273014101900001", RegionCurrent, "2025", "Jawa Barat", "Kota Bandung", "Sukasari"},
+		{"past", "3273994101900001", RegionPast, "2022", "Jawa Barat", "Kotamadya Bandung", "Cidadap Lama"},
+		{"unknown district", "3273984101900001", RegionUnknown, "", "Jawa Barat", "Kota Bandung", ""},
+		{"unknown state", "9901014101900001", RegionUnknown, "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := current.DecodeNIK(tt.nik, past)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Status != tt.wantStatus || r.Edition != tt.wantEdition {
+				t.Fatalf("got status %s, edition %q; want %s, %q", r.Status, r.Edition, tt.wantStatus, tt.wantEdition)
+			}
+			for _, got := range []struct {
+				level  string
+				region *Region
+				want   string
+			}{{"state", r.State, tt.wantState}, {"city", r.City, tt.wantCity}, {"district", r.District, tt.wantDistrict}} {
+				name := ""
+				if got.region != nil {
+					name = got.region.Name
+				}
+				if name != got.want {
+					t.Errorf("got %s %q, want %q", got.level, name, got.want)
+				}
+			}
+		})
+	}
+
+	if _, err := current.DecodeNIK("3273013102900001", past); !errors.Is(err, ErrInvalidNIK) {
+		t.Fatalf("invalid NIK: got %v, want ErrInvalidNIK", err)
+	}
+}
@@ -0,0 +1,46 @@
+package geoid
+
+import "strings"
+
+// Region is an administrative region of any level.
+type Region struct {
+	// Code is the dotted Kemendagri code, e.g. "11.01".
+	Code string `json:"code"`
+	// Name is the official name, e.g. "Kabupaten Aceh Selatan".
+	Name string `json:"name"`
+}
+
+// Level returns the level of the region, or "" when its code is invalid.
+func (r Region) Level() Level {
+	level, _ := LevelOf(r.Code)
+	return level
+}
+
+// ParentCode returns the code of the region directly above r, or "" for
+// states.
+func (r Region) ParentCode() string {
+	return ParentCode(r.Code)
+}
+
+// Type returns the administrative type of the region: provinsi, kabupaten
+// or kota, kecamatan, and desa or kelurahan. Village codes starting with 1
+// denote a kelurahan and those starting with 2 a desa.
+func (r Region) Type() string {
+	switch r.Level() {
+	case LevelState:
+		return "provinsi"
+	case LevelCity:
+		if strings.HasPrefix(r.Name, "Kota ") {
+			return "kota"
+		}
+		return "kabupaten"
+	case LevelDistrict:
+		return "kecamatan"
+	case LevelVillage:
+		if strings.HasPrefix(r.Code[strings.LastIndexByte(r.Code, '.')+1:], "1") {
+			return "kelurahan"
+		}
+		return "desa"
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package geoid
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// snapshotFormat is the version of the snapshot encoding.
+const snapshotFormat = 1
+
+// snapshot is the encoding of a dataset written by WriteSnapshot: gzipped
+// JSON holding the edition and every region as a [code, name] pair, each
+// parent's children in source order.
+type snapshot struct {
+	Format  int         `json:"format"`
+	Edition Edition     `json:"edition"`
+	Regions [][2]string `json:"regions"`
+}
+
+// WriteSnapshot writes the dataset to w in a compact single-file format
+// read by ReadSnapshot, suitable for embedding in a binary.
+func (d *Dataset) WriteSnapshot(w io.Writer) error {
+	parents := make([]string, 0, len(d.children))
+	for parent := range d.children {
+		parents = append(parents, parent)
+	}
+	sort.Strings(parents)
+
+	s := snapshot{Format: snapshotFormat, Edition: d.edition, Regions: make([][2]string, 0, len(d.regions))}
+	for _, parent := range parents {
+		for _, r := range d.children[parent] {
+			s.Regions = append(s.Regions, [2]string{r.Code, r.Name})
+		}
+	}
+
+	zw, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(zw).Encode(s); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ReadSnapshot reads a dataset written by WriteSnapshot.
+func ReadSnapshot(r io.Reader) (*Dataset, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	defer zr.Close()
+	var s snapshot
+	if err := json.NewDecoder(zr).Decode(&s); err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	if s.Format != snapshotFormat {
+		return nil, fmt.Errorf("reading snapshot: unsupported format %d", s.Format)
+	}
+
+	d := newDataset(s.Edition)
+	for _, r := range s.Regions {
+		d.add(Region{Code: r[0], Name: r[1]})
+	}
+	d.index()
+	return d, nil
+}
@@ -1,4 +1,5 @@
 #!/bin/bash
 
-# Run the Python extraction script
-python3 scripts/extract_data.py
+# Download wilayah.sql and regenerate the data directory.
+# Arguments are passed to "geo-id import", e.g. -file raw/wilayah.sql
+go run . import "$@"
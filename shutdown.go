@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+
+	"github.com/ikhsanfalakh/geo-id/internal/health"
+	"github.com/ikhsanfalakh/geo-id/internal/middleware"
+)
+
+// tracingFlushTimeout bounds how long buffered spans may take to export
+// once the servers have stopped.
+const tracingFlushTimeout = 5 * time.Second
+
+// shutdown holds everything stopped when the process receives SIGINT or
+// SIGTERM. grpc and admin are nil when those listeners are disabled.
+type shutdown struct {
+	tracker *health.Tracker
+	// delay is how long requests are still served after readiness turns
+	// OUT_OF_SERVICE (SHUTDOWN_DELAY)
+	delay time.Duration
+	// timeout bounds the wait for in-flight requests (SHUTDOWN_TIMEOUT)
+	timeout time.Duration
+
+	app       *fiber.App
+	grpc      *grpc.Server
+	admin     *http.Server
+	rateLimit *middleware.RateLimitConfig
+	// redis is the client of the Redis rate limit store, nil for the
+	// memory store
+	redis   *redis.Client
+	tracing func(context.Context) error
+}
+
+// run stops the process in order:
+//  1. Readiness reports OUT_OF_SERVICE while requests are still served for
+//     delay, so load balancers stop routing new traffic here.
+//  2. The HTTP, gRPC and admin servers stop accepting connections and
+//     finish in-flight requests, together within timeout; gRPC calls still
+//     running then are cancelled.
+//  3. The rate limiters stop, the Redis client closes and buffered spans
+//     are exported.
+func (s shutdown) run() error {
+	s.tracker.SetNotReady("shutting down")
+	if s.delay > 0 {
+		slog.Info("Draining before shutdown", "delay", s.delay.String())
+		time.Sleep(s.delay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	stop := func(name string, fn func(context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("stop %s: %w", name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	stop("HTTP server", s.app.ShutdownWithContext)
+	if s.grpc != nil {
+		stop("gRPC server", func(ctx context.Context) error { return stopGRPC(ctx, s.grpc) })
+	}
+	if s.admin != nil {
+		stop("admin server", s.admin.Shutdown)
+	}
+	wg.Wait()
+
+	s.rateLimit.Stop()
+	if s.redis != nil {
+		if err := s.redis.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close Redis client: %w", err))
+		}
+	}
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	defer cancelFlush()
+	if err := s.tracing(flushCtx); err != nil {
+		errs = append(errs, fmt.Errorf("flush traces: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// stopGRPC stops srv gracefully, cancelling the calls still running when
+// ctx is done.
+func stopGRPC(ctx context.Context, srv *grpc.Server) error {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		srv.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ikhsanfalakh/geo-id/internal/model"
+	"github.com/ikhsanfalakh/geo-id/internal/service"
+)
+
+// validateResult is the JSON output of the validate command.
+type validateResult struct {
+	DataDir string              `json:"data_dir"`
+	Valid   bool                `json:"valid"`
+	Error   string              `json:"error,omitempty"`
+	Edition *model.Edition      `json:"edition,omitempty"`
+	Counts  *model.RegionCounts `json:"counts,omitempty"`
+	Orphans int                 `json:"orphan_villages"`
+}
+
+// runValidate implements the "validate" command, which runs the checks
+// done at server startup against DATA_DIR. It exits with 1 when the
+// dataset is invalid.
+//
+//	geo-id validate [-json] [config flags]
+func runValidate(args []string) int {
+	fs := newFlagSet("validate")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	cfg, code := loadConfig(fs, args)
+	if cfg == nil {
+		return code
+	}
+	if !noArgs(fs) {
+		return exitUsage
+	}
+
+	result := validateResult{DataDir: cfg.Data.Dir}
+	svc := service.NewLocationService(cfg.Data.Dir)
+	orphans, err := svc.Validate()
+	if err == nil {
+		result.Edition, err = svc.Edition()
+	}
+	if err == nil {
+		var counts model.RegionCounts
+		if counts, err = svc.Counts(); err == nil {
+			result.Counts = &counts
+		}
+	}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Valid, result.Orphans = true, orphans
+	}
+
+	if *asJSON {
+		if err := printJSON(result); err != nil {
+			return exitFailure
+		}
+	} else if result.Valid {
+		fmt.Printf("%s is valid\n", result.DataDir)
+		fmt.Printf("Edition: %s (updated %s)\n", result.Edition.Name, result.Edition.UpdatedAt.Format(time.DateOnly))
+		fmt.Printf("States: %d, cities: %d, districts: %d, villages: %d\n",
+			result.Counts.States, result.Counts.Cities, result.Counts.Districts, result.Counts.Villages)
+		if orphans > 0 {
+			fmt.Printf("%d villages belong to districts missing from the source\n", orphans)
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "validate: %s: %s\n", result.DataDir, result.Error)
+	}
+
+	if !result.Valid {
+		return exitFailure
+	}
+	return exitOK
+}